@@ -0,0 +1,22 @@
+module github.com/matthiasbruns/awin-go/awin/awinotel
+
+go 1.22
+
+replace github.com/matthiasbruns/awin-go => ../..
+
+require (
+	github.com/matthiasbruns/awin-go v0.0.2-0.20261017182429-b1b2f145b026
+	go.opentelemetry.io/otel v1.31.0
+	go.opentelemetry.io/otel/metric v1.31.0
+	go.opentelemetry.io/otel/sdk v1.31.0
+	go.opentelemetry.io/otel/sdk/metric v1.31.0
+	go.opentelemetry.io/otel/trace v1.31.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/gocarina/gocsv v0.0.0-20211020200912-82fc2684cc48 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	golang.org/x/sys v0.26.0 // indirect
+)
@@ -0,0 +1,141 @@
+// Package awinotel records the metrics and traces of an awin.AwinClient with OpenTelemetry.
+package awinotel
+
+import (
+	"context"
+	"fmt"
+	"github.com/matthiasbruns/awin-go/awin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"time"
+)
+
+// Metrics
+// / awin.Metrics implementation with the following instruments:
+// / awin.requests Requests by awin.endpoint and http.response.status_code, 0 if no response was received
+// / awin.request.duration Time until the response header arrived in seconds by awin.endpoint
+// / awin.retries Retried attempts by awin.endpoint
+// / awin.download.bytes Downloaded (compressed) bytes by awin.endpoint
+// / awin.download.duration Time to download and decode a body in seconds by awin.endpoint and awin.result, ok or error
+// / awin.feed.rows Entries of complete downloads by awin.feed, feed list rows have the feed "list"
+type Metrics struct {
+	requests         metric.Int64Counter
+	requestDuration  metric.Float64Histogram
+	retries          metric.Int64Counter
+	downloadBytes    metric.Int64Counter
+	downloadDuration metric.Float64Histogram
+	feedRows         metric.Int64Histogram
+}
+
+// NewMetrics
+// / Returns Metrics creating its instruments with meter, e.g. otel.Meter("github.com/matthiasbruns/awin-go").
+func NewMetrics(meter metric.Meter) (*Metrics, error) {
+	m := &Metrics{}
+	var err error
+	if m.requests, err = meter.Int64Counter("awin.requests", metric.WithDescription("Requests sent to Awin.")); err != nil {
+		return nil, err
+	}
+	if m.requestDuration, err = meter.Float64Histogram("awin.request.duration", metric.WithUnit("s"), metric.WithDescription("Time until the response header of Awin arrived.")); err != nil {
+		return nil, err
+	}
+	if m.retries, err = meter.Int64Counter("awin.retries", metric.WithDescription("Retried attempts.")); err != nil {
+		return nil, err
+	}
+	if m.downloadBytes, err = meter.Int64Counter("awin.download.bytes", metric.WithUnit("By"), metric.WithDescription("Downloaded bytes, compressed feeds count their compressed bytes.")); err != nil {
+		return nil, err
+	}
+	if m.downloadDuration, err = meter.Float64Histogram("awin.download.duration", metric.WithUnit("s"), metric.WithDescription("Time to download and decode a response body.")); err != nil {
+		return nil, err
+	}
+	if m.feedRows, err = meter.Int64Histogram("awin.feed.rows", metric.WithDescription("Entries of complete feed downloads.")); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ObserveRequest
+// / Counts the request and records its duration.
+func (m *Metrics) ObserveRequest(endpoint awin.Endpoint, statusCode int, duration time.Duration) {
+	ctx := context.Background()
+	m.requests.Add(ctx, 1, metric.WithAttributes(endpointAttribute(endpoint), attribute.Int("http.response.status_code", statusCode)))
+	m.requestDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(endpointAttribute(endpoint)))
+}
+
+// ObserveRetry
+// / Counts the retry.
+func (m *Metrics) ObserveRetry(endpoint awin.Endpoint) {
+	m.retries.Add(context.Background(), 1, metric.WithAttributes(endpointAttribute(endpoint)))
+}
+
+// ObserveDownload
+// / Records bytes and duration of the download, and the rows if the body was read completely.
+func (m *Metrics) ObserveDownload(download awin.DownloadMetrics) {
+	ctx := context.Background()
+	result := "ok"
+	if download.Err != nil {
+		result = "error"
+	}
+	m.downloadBytes.Add(ctx, download.Bytes, metric.WithAttributes(endpointAttribute(download.Endpoint)))
+	m.downloadDuration.Record(ctx, download.Duration.Seconds(), metric.WithAttributes(endpointAttribute(download.Endpoint), attribute.String("awin.result", result)))
+
+	if download.Complete {
+		feed := "list"
+		if download.Endpoint != awin.EndpointFeedList {
+			feed = strings.Join(download.FeedIds, ",")
+		}
+		m.feedRows.Record(ctx, int64(download.Rows), metric.WithAttributes(attribute.String("awin.feed", feed)))
+	}
+}
+
+func endpointAttribute(endpoint awin.Endpoint) attribute.KeyValue {
+	return attribute.String("awin.endpoint", string(endpoint))
+}
+
+// Tracer
+// / awin.Tracer creating OpenTelemetry spans.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer
+// / Returns a Tracer starting its spans with tracer, e.g. otel.Tracer("github.com/matthiasbruns/awin-go").
+func NewTracer(tracer trace.Tracer) *Tracer {
+	return &Tracer{tracer: tracer}
+}
+
+// Start
+// / Starts a client span named name as child of the span in ctx.
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, awin.Span) {
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+	return ctx, otelSpan{span: span}
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s otelSpan) SetAttribute(key string, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		s.span.SetAttributes(attribute.String(key, v))
+	case int:
+		s.span.SetAttributes(attribute.Int(key, v))
+	case int64:
+		s.span.SetAttributes(attribute.Int64(key, v))
+	case []string:
+		s.span.SetAttributes(attribute.StringSlice(key, v))
+	default:
+		s.span.SetAttributes(attribute.String(key, fmt.Sprint(v)))
+	}
+}
+
+func (s otelSpan) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
@@ -0,0 +1,97 @@
+package awinotel
+
+import (
+	"context"
+	"errors"
+	"github.com/matthiasbruns/awin-go/awin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	metrics, err := NewMetrics(provider.Meter("test"))
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	var _ awin.Metrics = metrics
+	metrics.ObserveRequest(awin.EndpointDataFeed, 503, time.Second)
+	metrics.ObserveRequest(awin.EndpointDataFeed, 200, time.Second)
+	metrics.ObserveRetry(awin.EndpointDataFeed)
+	metrics.ObserveDownload(awin.DownloadMetrics{Endpoint: awin.EndpointDataFeed, FeedIds: []string{"1"}, Rows: 20, Bytes: 1000, Duration: time.Second, Complete: true})
+	metrics.ObserveDownload(awin.DownloadMetrics{Endpoint: awin.EndpointDataFeed, FeedIds: []string{"1"}, Bytes: 200, Err: errors.New("truncated")})
+	// Streams closed early keep the rows of the last complete download
+	metrics.ObserveDownload(awin.DownloadMetrics{Endpoint: awin.EndpointDataFeed, FeedIds: []string{"1"}, Rows: 1, Bytes: 100})
+
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	sums := map[string]int64{}
+	rows := int64(-1)
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			switch d := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, point := range d.DataPoints {
+					sums[m.Name] += point.Value
+				}
+			case metricdata.Histogram[int64]:
+				if m.Name == "awin.feed.rows" && len(d.DataPoints) == 1 && d.DataPoints[0].Count == 1 {
+					if feed, _ := d.DataPoints[0].Attributes.Value("awin.feed"); feed == attribute.StringValue("1") {
+						rows = d.DataPoints[0].Sum
+					}
+				}
+			}
+		}
+	}
+
+	if sums["awin.requests"] != 2 || sums["awin.retries"] != 1 || sums["awin.download.bytes"] != 1300 || rows != 20 {
+		t.Fatalf("Invalid metrics recorded %v, rows %d", sums, rows)
+	}
+}
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := NewTracer(provider.Tracer("test"))
+
+	var _ awin.Tracer = tracer
+	_, span := tracer.Start(context.Background(), awin.SpanFetchDataFeed)
+	span.SetAttribute("awin.feed_ids", []string{"1", "2"})
+	span.SetAttribute("awin.rows", 20)
+	span.SetAttribute("awin.bytes", int64(1000))
+	span.End(errors.New("truncated"))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Invalid amount of spans %d", len(spans))
+	}
+	ended := spans[0]
+	if ended.Name() != awin.SpanFetchDataFeed || ended.Status().Code != codes.Error || len(ended.Events()) != 1 {
+		t.Fatalf("Invalid span %v", ended)
+	}
+
+	expected := []attribute.KeyValue{
+		attribute.StringSlice("awin.feed_ids", []string{"1", "2"}),
+		attribute.Int("awin.rows", 20),
+		attribute.Int64("awin.bytes", 1000),
+	}
+	if len(ended.Attributes()) != len(expected) {
+		t.Fatalf("Invalid attributes %v", ended.Attributes())
+	}
+	for i, kv := range ended.Attributes() {
+		if kv.Key != expected[i].Key || kv.Value.Emit() != expected[i].Value.Emit() {
+			t.Fatalf("Invalid attribute %v, expected %v", kv, expected[i])
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Package awinprom records the metrics of an awin.AwinClient with Prometheus.
+package awinprom
+
+import (
+	"github.com/matthiasbruns/awin-go/awin"
+	"github.com/prometheus/client_golang/prometheus"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metrics
+// / awin.Metrics implementation with the following collectors, all prefixed with awin_:
+// / requests_total Requests by endpoint and status, status is "error" if no response was received
+// / request_duration_seconds Time until the response header arrived by endpoint
+// / retries_total Retried attempts by endpoint
+// / download_bytes_total Downloaded (compressed) bytes by endpoint
+// / download_duration_seconds Time to download and decode a body by endpoint and result, ok or error
+// / feed_rows Entries of the last complete download by feed, feed list rows have the feed "list"
+type Metrics struct {
+	requests         *prometheus.CounterVec
+	requestDuration  *prometheus.HistogramVec
+	retries          *prometheus.CounterVec
+	downloadBytes    *prometheus.CounterVec
+	downloadDuration *prometheus.HistogramVec
+	feedRows         *prometheus.GaugeVec
+}
+
+// NewMetrics
+// / Returns Metrics with its collectors registered at registerer, e.g. prometheus.DefaultRegisterer.
+func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "awin",
+			Name:      "requests_total",
+			Help:      "Requests sent to Awin by endpoint and status.",
+		}, []string{"endpoint", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "awin",
+			Name:      "request_duration_seconds",
+			Help:      "Time until the response header of Awin arrived.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "awin",
+			Name:      "retries_total",
+			Help:      "Retried attempts by endpoint.",
+		}, []string{"endpoint"}),
+		downloadBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "awin",
+			Name:      "download_bytes_total",
+			Help:      "Downloaded bytes by endpoint, compressed feeds count their compressed bytes.",
+		}, []string{"endpoint"}),
+		downloadDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "awin",
+			Name:      "download_duration_seconds",
+			Help:      "Time to download and decode a response body.",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 2, 14),
+		}, []string{"endpoint", "result"}),
+		feedRows: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "awin",
+			Name:      "feed_rows",
+			Help:      "Entries of the last complete download of a feed.",
+		}, []string{"feed"}),
+	}
+
+	for _, collector := range []prometheus.Collector{m.requests, m.requestDuration, m.retries, m.downloadBytes, m.downloadDuration, m.feedRows} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// ObserveRequest
+// / Counts the request and records its duration.
+func (m *Metrics) ObserveRequest(endpoint awin.Endpoint, statusCode int, duration time.Duration) {
+	status := "error"
+	if statusCode != 0 {
+		status = strconv.Itoa(statusCode)
+	}
+	m.requests.WithLabelValues(string(endpoint), status).Inc()
+	m.requestDuration.WithLabelValues(string(endpoint)).Observe(duration.Seconds())
+}
+
+// ObserveRetry
+// / Counts the retry.
+func (m *Metrics) ObserveRetry(endpoint awin.Endpoint) {
+	m.retries.WithLabelValues(string(endpoint)).Inc()
+}
+
+// ObserveDownload
+// / Records bytes and duration of the download, and the rows if the body was read completely.
+func (m *Metrics) ObserveDownload(download awin.DownloadMetrics) {
+	result := "ok"
+	if download.Err != nil {
+		result = "error"
+	}
+	m.downloadBytes.WithLabelValues(string(download.Endpoint)).Add(float64(download.Bytes))
+	m.downloadDuration.WithLabelValues(string(download.Endpoint), result).Observe(download.Duration.Seconds())
+
+	if download.Complete {
+		m.feedRows.WithLabelValues(feedLabel(download)).Set(float64(download.Rows))
+	}
+}
+
+func feedLabel(download awin.DownloadMetrics) string {
+	if download.Endpoint == awin.EndpointFeedList {
+		return "list"
+	}
+	return strings.Join(download.FeedIds, ",")
+}
@@ -0,0 +1,59 @@
+package awinprom
+
+import (
+	"errors"
+	"github.com/matthiasbruns/awin-go/awin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics, err := NewMetrics(registry)
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if _, err := NewMetrics(registry); err == nil {
+		t.Fatal("expected error for duplicate registration")
+	}
+
+	var _ awin.Metrics = metrics
+	metrics.ObserveRequest(awin.EndpointDataFeed, 503, time.Second)
+	metrics.ObserveRequest(awin.EndpointDataFeed, 200, time.Second)
+	metrics.ObserveRequest(awin.EndpointFeedList, 0, time.Second)
+	metrics.ObserveRetry(awin.EndpointDataFeed)
+	metrics.ObserveDownload(awin.DownloadMetrics{Endpoint: awin.EndpointDataFeed, FeedIds: []string{"1"}, Rows: 20, Bytes: 1000, Duration: time.Second, Complete: true})
+	metrics.ObserveDownload(awin.DownloadMetrics{Endpoint: awin.EndpointDataFeed, FeedIds: []string{"1"}, Rows: 5, Bytes: 200, Err: errors.New("truncated")})
+	// Streams closed early keep the rows of the last complete download
+	metrics.ObserveDownload(awin.DownloadMetrics{Endpoint: awin.EndpointDataFeed, FeedIds: []string{"1"}, Rows: 1, Bytes: 100})
+
+	for _, test := range []struct {
+		collector prometheus.Collector
+		labels    []string
+		expected  float64
+	}{
+		{metrics.requests, []string{"data_feed", "503"}, 1},
+		{metrics.requests, []string{"data_feed", "200"}, 1},
+		{metrics.requests, []string{"feed_list", "error"}, 1},
+		{metrics.retries, []string{"data_feed"}, 1},
+		{metrics.downloadBytes, []string{"data_feed"}, 1300},
+		{metrics.feedRows, []string{"1"}, 20},
+	} {
+		var value float64
+		switch vec := test.collector.(type) {
+		case *prometheus.CounterVec:
+			value = testutil.ToFloat64(vec.WithLabelValues(test.labels...))
+		case *prometheus.GaugeVec:
+			value = testutil.ToFloat64(vec.WithLabelValues(test.labels...))
+		}
+		if value != test.expected {
+			t.Fatalf("%v: expected %v, received %v", test.labels, test.expected, value)
+		}
+	}
+
+	if count := testutil.CollectAndCount(metrics.downloadDuration); count != 2 {
+		t.Fatalf("expected 2 download duration series, received %d", count)
+	}
+}
@@ -0,0 +1,300 @@
+package awintest
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"github.com/gocarina/gocsv"
+	"github.com/matthiasbruns/awin-go/awin"
+	"sort"
+)
+
+// extraColumns returns the sorted names of the Extra values of entries, served after the regular columns
+func extraColumns(entries []awin.DataFeedEntry) []awin.DataFeedColumn {
+	names := map[string]bool{}
+	for i := range entries {
+		for name := range entries[i].Extra {
+			names[name] = true
+		}
+	}
+
+	columns := make([]awin.DataFeedColumn, 0, len(names))
+	for name := range names {
+		columns = append(columns, awin.DataFeedColumn(name))
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i] < columns[j] })
+	return columns
+}
+
+// encodeFeed renders entries like Awin does for feedUrl: the requested columns in the requested format and compression
+func encodeFeed(entries []awin.DataFeedEntry, feedUrl *awin.DataFeedURL) ([]byte, error) {
+	columns := feedUrl.Columns
+	if len(columns) == 0 {
+		columns = append(awin.DataFeedColumns(), extraColumns(entries)...)
+	}
+
+	var body []byte
+	var err error
+	switch feedUrl.Format {
+	case awin.FormatJson:
+		body, err = encodeJson(entries, columns)
+	case awin.FormatXml:
+		body, err = encodeXml(entries, columns)
+	case awin.FormatXmlTree:
+		body, err = encodeXmlTree(entries, columns)
+	default:
+		delimiter := feedUrl.Delimiter
+		if delimiter == 0 {
+			delimiter = awin.DelimiterComma
+		}
+		body, err = encodeCsv(entries, columns, rune(delimiter))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch feedUrl.Compression {
+	case awin.CompressionNone:
+		return body, nil
+	case awin.CompressionZip:
+		return zipBody(body)
+	default:
+		return GzipBody(body)
+	}
+}
+
+func encodeCsv(entries []awin.DataFeedEntry, columns []awin.DataFeedColumn, delimiter rune) ([]byte, error) {
+	var b bytes.Buffer
+	writer := csv.NewWriter(&b)
+	writer.Comma = delimiter
+
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		record[i] = string(column)
+	}
+	if err := writer.Write(record); err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		for j, column := range columns {
+			record[j] = entries[i].Value(column)
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	return b.Bytes(), writer.Error()
+}
+
+func encodeJson(entries []awin.DataFeedEntry, columns []awin.DataFeedColumn) ([]byte, error) {
+	products := make([]map[string]string, len(entries))
+	for i := range entries {
+		products[i] = make(map[string]string, len(columns))
+		for _, column := range columns {
+			products[i][string(column)] = entries[i].Value(column)
+		}
+	}
+	return json.Marshal(products)
+}
+
+func encodeXml(entries []awin.DataFeedEntry, columns []awin.DataFeedColumn) ([]byte, error) {
+	var b bytes.Buffer
+	encoder := xml.NewEncoder(&b)
+
+	products := xml.StartElement{Name: xml.Name{Local: "products"}}
+	if err := encoder.EncodeToken(products); err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		product := xml.StartElement{Name: xml.Name{Local: "product"}}
+		if err := encoder.EncodeToken(product); err != nil {
+			return nil, err
+		}
+		for _, column := range columns {
+			if err := encoder.EncodeElement(entries[i].Value(column), xml.StartElement{Name: xml.Name{Local: string(column)}}); err != nil {
+				return nil, err
+			}
+		}
+		if err := encoder.EncodeToken(product.End()); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.EncodeToken(products.End()); err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// xmlTreeElement is a column rendered as element named name in Awin's tree xml
+type xmlTreeElement struct {
+	name   string
+	column awin.DataFeedColumn
+}
+
+// xmlTreeGroup nests elements of a product in Awin's tree xml, elements of groups without name are children of the
+// product
+type xmlTreeGroup struct {
+	name     string
+	elements []xmlTreeElement
+}
+
+// xmlTreeGroups is the layout of a product in Awin's tree xml. The product id, merchant and currency are
+// attributes of the prod, merchant and price elements, columns missing here are elements named by the column.
+var xmlTreeGroups = []xmlTreeGroup{
+	{"", []xmlTreeElement{{"pId", awin.ColumnMerchantProductId}}},
+	{"text", []xmlTreeElement{{"name", awin.ColumnProductName}, {"desc", awin.ColumnDescription}, {"spec", awin.ColumnSpecifications}, {"promo", awin.ColumnPromotionalText}}},
+	{"uri", []xmlTreeElement{{"awTrack", awin.ColumnAwDeepLink}, {"awImage", awin.ColumnAwImageUrl}, {"awThumb", awin.ColumnAwThumbUrl}, {"mImage", awin.ColumnMerchantImageUrl}, {"mThumb", awin.ColumnMerchantThumbUrl}, {"mLink", awin.ColumnMerchantDeepLink}}},
+	{"price", []xmlTreeElement{{"buynow", awin.ColumnSearchPrice}, {"store", awin.ColumnStorePrice}, {"rrp", awin.ColumnRrpPrice}, {"delivery", awin.ColumnDeliveryCost}}},
+	{"cat", []xmlTreeElement{{"awCatId", awin.ColumnCategoryId}, {"awCat", awin.ColumnCategoryName}, {"mCat", awin.ColumnMerchantCategory}}},
+	{"", []xmlTreeElement{{"brand", awin.ColumnBrandName}, {"brandId", awin.ColumnBrandId}}},
+}
+
+// encodeXmlTree renders entries like Awin's tree xml: products grouped by merchant, their columns nested as in
+// xmlTreeGroups
+func encodeXmlTree(entries []awin.DataFeedEntry, columns []awin.DataFeedColumn) ([]byte, error) {
+	requested := make(map[awin.DataFeedColumn]bool, len(columns))
+	for _, column := range columns {
+		requested[column] = true
+	}
+
+	// Attributes and grouped columns, the remaining ones are rendered as plain elements
+	rendered := map[awin.DataFeedColumn]bool{awin.ColumnAwProductId: true, awin.ColumnMerchantId: true, awin.ColumnMerchantName: true}
+	var groups []xmlTreeGroup
+	for _, group := range xmlTreeGroups {
+		var elements []xmlTreeElement
+		for _, element := range group.elements {
+			if requested[element.column] {
+				elements = append(elements, element)
+				rendered[element.column] = true
+			}
+		}
+		if len(elements) > 0 {
+			groups = append(groups, xmlTreeGroup{name: group.name, elements: elements})
+		}
+		// The currency is an attribute of the prices, without them it is a plain element
+		if group.name == "price" && len(elements) > 0 {
+			rendered[awin.ColumnCurrency] = true
+		}
+	}
+	for _, column := range columns {
+		if !rendered[column] {
+			groups = append(groups, xmlTreeGroup{elements: []xmlTreeElement{{string(column), column}}})
+		}
+	}
+
+	var b bytes.Buffer
+	encoder := xml.NewEncoder(&b)
+
+	attr := func(attrs []xml.Attr, column awin.DataFeedColumn, name string, entry *awin.DataFeedEntry) []xml.Attr {
+		if requested[column] {
+			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: name}, Value: entry.Value(column)})
+		}
+		return attrs
+	}
+
+	feed := xml.StartElement{Name: xml.Name{Local: "merchantProductFeed"}}
+	if err := encoder.EncodeToken(feed); err != nil {
+		return nil, err
+	}
+	merchant := xml.StartElement{Name: xml.Name{Local: "merchant"}}
+	for i := range entries {
+		entry := &entries[i]
+		if i == 0 || entry.MerchantId != entries[i-1].MerchantId || entry.MerchantName != entries[i-1].MerchantName {
+			if i > 0 {
+				if err := encoder.EncodeToken(merchant.End()); err != nil {
+					return nil, err
+				}
+			}
+			merchant.Attr = attr(attr(nil, awin.ColumnMerchantId, "id", entry), awin.ColumnMerchantName, "name", entry)
+			if err := encoder.EncodeToken(merchant); err != nil {
+				return nil, err
+			}
+		}
+
+		product := xml.StartElement{Name: xml.Name{Local: "prod"}, Attr: attr(nil, awin.ColumnAwProductId, "id", entry)}
+		if err := encoder.EncodeToken(product); err != nil {
+			return nil, err
+		}
+		for _, group := range groups {
+			start := xml.StartElement{Name: xml.Name{Local: group.name}}
+			if group.name != "" {
+				if group.name == "price" {
+					start.Attr = attr(nil, awin.ColumnCurrency, "curr", entry)
+				}
+				if err := encoder.EncodeToken(start); err != nil {
+					return nil, err
+				}
+			}
+			for _, element := range group.elements {
+				if err := encoder.EncodeElement(entry.Value(element.column), xml.StartElement{Name: xml.Name{Local: element.name}}); err != nil {
+					return nil, err
+				}
+			}
+			if group.name != "" {
+				if err := encoder.EncodeToken(start.End()); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if err := encoder.EncodeToken(product.End()); err != nil {
+			return nil, err
+		}
+	}
+	if len(entries) > 0 {
+		if err := encoder.EncodeToken(merchant.End()); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.EncodeToken(feed.End()); err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func encodeFeedList(rows []awin.DataFeedListRow) ([]byte, error) {
+	return gocsv.MarshalBytes(&rows)
+}
+
+// GzipBody
+// / Returns body gzip compressed, like Awin compresses feeds by default.
+func GzipBody(body []byte) ([]byte, error) {
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	if _, err := gz.Write(body); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func zipBody(body []byte) ([]byte, error) {
+	var b bytes.Buffer
+	archive := zip.NewWriter(&b)
+	file, err := archive.Create("datafeed.csv")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Write(body); err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
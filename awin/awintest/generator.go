@@ -0,0 +1,289 @@
+package awintest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/matthiasbruns/awin-go/awin"
+	"io"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GeneratorOptions
+// / Controls the synthetic feeds of the Generate and Write functions. The same options always produce the same output.
+// / Seed Seed of the random values
+// / Rows Number of entries or feed list rows
+// / Columns The columns of generated feeds in this order, all columns if empty
+// / Delimiter The csv delimiter, DelimiterComma if 0
+// / Unicode Mixes non ASCII words, like umlauts, CJK and emoji, into the texts
+// / Messy Adds quotes, delimiters and newlines to the texts that need csv quoting
+// / MissingColumns Number of randomly picked columns left out of the csv header and rows
+type GeneratorOptions struct {
+	Seed           int64
+	Rows           int
+	Columns        []awin.DataFeedColumn
+	Delimiter      awin.DataFeedDelimiter
+	Unicode        bool
+	Messy          bool
+	MissingColumns int
+}
+
+var (
+	generatorWords        = strings.Fields("classic sport running leather cotton slim fit winter summer outdoor premium basic organic vintage waterproof lightweight comfort travel kids women men")
+	generatorUnicodeWords = strings.Fields("Größe Übergröße café crème naïve 日本語 中文 한국어 Ελληνικά кириллица 🎉 👟 ✓ ½")
+	generatorProducts     = strings.Fields("shoe sneaker boot jacket shirt dress jeans backpack watch lamp chair kettle headphones")
+	generatorBrands       = strings.Fields("Acme Globex Initech Umbrella Stark Wayne Hooli Vandelay")
+	generatorCurrencies   = strings.Fields("EUR GBP USD CHF SEK PLN")
+	generatorLanguages    = strings.Fields("de en fr nl it es")
+	generatorRegions      = strings.Fields("DE GB FR NL IT ES US")
+	generatorMemberships  = []string{"active", "active", "active", "Not Joined", "pending", "suspended"}
+	generatorEpoch        = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// GenerateEntries
+// / Returns options.Rows realistic entries. Columns and MissingColumns only affect the csv written by WriteDataFeedCsv.
+func GenerateEntries(options GeneratorOptions) []awin.DataFeedEntry {
+	g := newGenerator(options)
+	entries := make([]awin.DataFeedEntry, options.Rows)
+	for i := range entries {
+		entries[i] = g.entry(i)
+	}
+	return entries
+}
+
+// GenerateDataFeedListRows
+// / Returns options.Rows feed list rows with feed ids 1 to Rows, most of them with an active membership.
+func GenerateDataFeedListRows(options GeneratorOptions) []awin.DataFeedListRow {
+	g := newGenerator(options)
+	rows := make([]awin.DataFeedListRow, options.Rows)
+	for i := range rows {
+		rows[i] = g.listRow(i)
+	}
+	return rows
+}
+
+// GeneratedColumns
+// / Returns the columns WriteDataFeedCsv writes for options, without the missing ones.
+func GeneratedColumns(options GeneratorOptions) []awin.DataFeedColumn {
+	columns := options.Columns
+	if len(columns) == 0 {
+		columns = awin.DataFeedColumns()
+	}
+
+	// An own source, so the picked columns do not depend on the number of rows
+	r := rand.New(rand.NewSource(options.Seed))
+	kept := append([]awin.DataFeedColumn{}, columns...)
+	for i := 0; i < options.MissingColumns && len(kept) > 0; i++ {
+		index := r.Intn(len(kept))
+		kept = append(kept[:index], kept[index+1:]...)
+	}
+	return kept
+}
+
+// WriteDataFeedCsv
+// / Writes a feed of options.Rows entries as csv to w, row by row so millions of rows need no memory.
+func WriteDataFeedCsv(w io.Writer, options GeneratorOptions) error {
+	columns := GeneratedColumns(options)
+	writer := newGeneratorCsvWriter(w, options)
+
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		record[i] = string(column)
+	}
+	if err := writer.Write(record); err != nil {
+		return err
+	}
+
+	g := newGenerator(options)
+	for i := 0; i < options.Rows; i++ {
+		entry := g.entry(i)
+		for j, column := range columns {
+			record[j] = entry.Value(column)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteDataFeedListCsv
+// / Writes a feed list of options.Rows rows as csv to w.
+func WriteDataFeedListCsv(w io.Writer, options GeneratorOptions) error {
+	writer := newGeneratorCsvWriter(w, options)
+	header := []string{"Advertiser ID", "Advertiser Name", "Primary Region", "Membership Status", "Feed ID", "Feed Name",
+		"Language", "Vertical", "Last Imported", "Last Checked", "No of products", "URL"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	g := newGenerator(options)
+	for i := 0; i < options.Rows; i++ {
+		row := g.listRow(i)
+		record := []string{row.AdvertiserID, row.AdvertiserName, row.PrimaryRegion, row.MembershipStatus, row.FeedID,
+			row.FeedName, row.Language, row.Vertical, row.LastImported, row.LastChecked, row.NoOfProducts, row.URL}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func newGeneratorCsvWriter(w io.Writer, options GeneratorOptions) *csv.Writer {
+	writer := csv.NewWriter(w)
+	if options.Delimiter != 0 {
+		writer.Comma = rune(options.Delimiter)
+	}
+	return writer
+}
+
+// generator draws all values from one seeded source, the order of the calls defines the output
+type generator struct {
+	rand    *rand.Rand
+	options GeneratorOptions
+}
+
+func newGenerator(options GeneratorOptions) *generator {
+	return &generator{rand: rand.New(rand.NewSource(options.Seed)), options: options}
+}
+
+func (g *generator) pick(values []string) string {
+	return values[g.rand.Intn(len(values))]
+}
+
+// text returns n words, with unicode and messy parts if enabled
+func (g *generator) text(n int) string {
+	words := make([]string, n)
+	for i := range words {
+		if g.options.Unicode && g.rand.Intn(4) == 0 {
+			words[i] = g.pick(generatorUnicodeWords)
+		} else {
+			words[i] = g.pick(generatorWords)
+		}
+	}
+	text := strings.Join(words, " ")
+
+	if g.options.Messy {
+		switch g.rand.Intn(5) {
+		case 0:
+			text = `"` + text + `" 12" display`
+		case 1:
+			text = strings.Replace(text, " ", "\n", 1)
+		case 2:
+			text += ", " + string(rune(g.delimiter())) + " " + g.pick(generatorWords)
+		}
+	}
+	return text
+}
+
+func (g *generator) delimiter() awin.DataFeedDelimiter {
+	if g.options.Delimiter == 0 {
+		return awin.DelimiterComma
+	}
+	return g.options.Delimiter
+}
+
+func (g *generator) price(max int) string {
+	return fmt.Sprintf("%d.%02d", g.rand.Intn(max), g.rand.Intn(100))
+}
+
+func (g *generator) date() string {
+	return generatorEpoch.Add(time.Duration(g.rand.Intn(365*24*60)) * time.Minute).Format("2006-01-02 15:04:05")
+}
+
+// gtin returns a 13 digit GTIN with a valid check digit
+func (g *generator) gtin() string {
+	digits := make([]byte, 13)
+	sum := 0
+	for i := 0; i < 12; i++ {
+		digit := g.rand.Intn(10)
+		digits[i] = byte('0' + digit)
+		if i%2 == 0 {
+			sum += digit
+		} else {
+			sum += 3 * digit
+		}
+	}
+	digits[12] = byte('0' + (10-sum%10)%10)
+	return string(digits)
+}
+
+func (g *generator) entry(i int) awin.DataFeedEntry {
+	id := strconv.Itoa(100000 + i)
+	merchantId := strconv.Itoa(1000 + g.rand.Intn(50))
+	product := g.pick(generatorProducts)
+	brand := g.pick(generatorBrands)
+	searchPrice := g.price(500)
+
+	entry := awin.DataFeedEntry{
+		AwProductId:             id,
+		ProductName:             brand + " " + g.text(2) + " " + product,
+		MerchantProductId:       "SKU-" + strconv.Itoa(g.rand.Intn(1000000)),
+		MerchantId:              merchantId,
+		MerchantName:            g.pick(generatorBrands) + " Store",
+		AwDeepLink:              "https://www.awin1.com/pclick.php?p=" + id + "&a=123&m=" + merchantId,
+		MerchantDeepLink:        "https://shop.example.com/p/" + id,
+		MerchantImageUrl:        "https://shop.example.com/img/" + id + ".jpg",
+		AwImageUrl:              "https://images2.productserve.com/?w=200&h=200&url=" + id,
+		AwThumbUrl:              "https://images2.productserve.com/?w=70&h=70&url=" + id,
+		Description:             g.text(5 + g.rand.Intn(20)),
+		ProductShortDescription: g.text(4),
+		MerchantCategory:        strings.ToUpper(product[:1]) + product[1:] + "s",
+		CategoryName:            strings.ToUpper(product[:1]) + product[1:] + "s",
+		CategoryId:              strconv.Itoa(g.rand.Intn(600)),
+		SearchPrice:             searchPrice,
+		StorePrice:              searchPrice,
+		RrpPrice:                g.price(800),
+		DeliveryCost:            g.price(10),
+		Currency:                g.pick(generatorCurrencies),
+		Language:                g.pick(generatorLanguages),
+		LastUpdated:             g.date(),
+		DataFeedId:              strconv.Itoa(1 + g.rand.Intn(20)),
+		BrandName:               brand,
+		BrandId:                 strconv.Itoa(g.rand.Intn(10000)),
+		Colour:                  g.pick(strings.Fields("black white red blue green grey")),
+		Condition:               "new",
+		InStock:                 strconv.Itoa(g.rand.Intn(2)),
+		StockQuantity:           strconv.Itoa(g.rand.Intn(500)),
+		IsForSale:               "1",
+		AverageRating:           fmt.Sprintf("%.1f", 1+4*g.rand.Float64()),
+		Reviews:                 strconv.Itoa(g.rand.Intn(2000)),
+		Keywords:                g.text(3),
+		Ean:                     g.gtin(),
+		ProductGtin:             g.gtin(),
+		Mpn:                     "MPN-" + strconv.Itoa(g.rand.Intn(100000)),
+	}
+
+	// Remaining columns get short texts, so every column of the feed has content
+	value := reflect.ValueOf(&entry).Elem()
+	for index := 0; index < value.NumField(); index++ {
+		if field := value.Field(index); field.Kind() == reflect.String && field.String() == "" {
+			field.SetString(g.text(1 + g.rand.Intn(3)))
+		}
+	}
+	return entry
+}
+
+func (g *generator) listRow(i int) awin.DataFeedListRow {
+	return awin.DataFeedListRow{
+		AdvertiserID:     strconv.Itoa(1000 + i),
+		AdvertiserName:   g.pick(generatorBrands) + " " + g.text(1),
+		PrimaryRegion:    g.pick(generatorRegions),
+		MembershipStatus: g.pick(generatorMemberships),
+		FeedID:           strconv.Itoa(i + 1),
+		FeedName:         g.text(2),
+		Language:         g.pick(generatorLanguages),
+		Vertical:         g.pick(strings.Fields("Fashion Electronics Home Sports Travel")),
+		LastImported:     g.date(),
+		LastChecked:      g.date(),
+		NoOfProducts:     strconv.Itoa(g.rand.Intn(100000)),
+		URL:              "https://productdata.awin.com/datafeed/download/apikey/APIKEY/fid/" + strconv.Itoa(i+1) + "/",
+	}
+}
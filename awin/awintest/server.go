@@ -0,0 +1,313 @@
+// Package awintest provides a fake Awin productdata server for tests of code using the awin package.
+package awintest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/matthiasbruns/awin-go/awin"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// chunkSize is the size of the body chunks a Fault with Delay sends
+const chunkSize = 1024
+
+// Server
+// / httptest based fake of the feed list and feed download endpoints.
+// / Downloads are rendered from the added feeds in the format, delimiter, compression and columns the url asks for.
+// / Columns may be any known column or a custom one carried in the Extra values of the served entries.
+// / Requests with another api key get 401, invalid url segments 400 and unknown feed ids 404, the response body
+// / names the problem so it shows up in awin.APIError.Body.
+type Server struct {
+	*httptest.Server
+	ApiKey string
+
+	mutex    sync.Mutex
+	rows     []awin.DataFeedListRow
+	feeds    map[string][]awin.DataFeedEntry
+	faults   []*Fault
+	requests []Request
+}
+
+// Fault
+// / Makes matching requests fail or misbehave, see Server.AddFault.
+// / FeedId Only downloads including this feed match, all downloads if empty
+// / List Matches feed list requests instead of downloads
+// / StatusCode Responds with this status and Body instead of the feed, ignored if 0
+// / Header Added to the response, e.g. Retry-After
+// / Delay Sleeps before every chunk of 1 KiB of the body, simulating a slow download
+// / TruncateAfter Closes the connection after this many bytes of the (compressed) body, ignored if 0
+// / Times Number of requests the fault applies to, all requests if 0
+type Fault struct {
+	FeedId        string
+	List          bool
+	StatusCode    int
+	Body          string
+	Header        http.Header
+	Delay         time.Duration
+	TruncateAfter int
+	Times         int
+
+	applied int
+}
+
+// Request
+// / A request received by the Server. FeedUrl is the parsed download url, nil for feed list requests.
+type Request struct {
+	Path    string
+	Header  http.Header
+	FeedUrl *awin.DataFeedURL
+}
+
+// NewServer
+// / Starts a Server accepting apiKey, stop it with Close.
+func NewServer(apiKey string) *Server {
+	s := &Server{ApiKey: apiKey, feeds: map[string][]awin.DataFeedEntry{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client
+// / Returns an AwinClient for the server, opts are applied after the base url and api key.
+func (s *Server) Client(opts ...awin.Option) *awin.AwinClient {
+	return awin.New(s.ApiKey, append([]awin.Option{awin.WithBaseUrl(s.URL)}, opts...)...)
+}
+
+// AddFeed
+// / Adds row to the feed list and serves entries for its FeedID. An empty MembershipStatus is set to active.
+func (s *Server) AddFeed(row awin.DataFeedListRow, entries []awin.DataFeedEntry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if row.MembershipStatus == "" {
+		row.MembershipStatus = awin.MembershipStatusActive.String()
+	}
+	for i := range s.rows {
+		if s.rows[i].FeedID == row.FeedID {
+			s.rows[i] = row
+			s.feeds[row.FeedID] = entries
+			return
+		}
+	}
+	s.rows = append(s.rows, row)
+	s.feeds[row.FeedID] = entries
+}
+
+// AddFault
+// / Applies fault to the matching requests, faults are checked in the order they were added.
+func (s *Server) AddFault(fault Fault) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.faults = append(s.faults, &fault)
+}
+
+// Requests
+// / Returns all requests received so far.
+func (s *Server) Requests() []Request {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Request{}, s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.EscapedPath()
+	request := Request{Path: path, Header: r.Header.Clone()}
+
+	switch {
+	case strings.Contains(path, "/datafeed/list/"):
+		s.record(request)
+		if !s.authorized(listApiKey(path)) {
+			http.Error(w, "invalid api key", http.StatusUnauthorized)
+			return
+		}
+		s.serveList(w, r)
+	case strings.Contains(path, "/datafeed/download/"):
+		feedUrl, err := awin.ParseDataFeedURL("http://" + r.Host + path)
+		request.FeedUrl = feedUrl
+		s.record(request)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !s.authorized(feedUrl.ApiKey) {
+			http.Error(w, "invalid api key", http.StatusUnauthorized)
+			return
+		}
+		s.serveFeed(w, r, feedUrl)
+	default:
+		s.record(request)
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) record(request Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.requests = append(s.requests, request)
+}
+
+// listApiKey returns the api key of a feed list path, empty if it has none
+func listApiKey(path string) string {
+	index := strings.Index(path, "/apikey/")
+	if index < 0 {
+		return ""
+	}
+	return strings.Trim(path[index+len("/apikey/"):], "/")
+}
+
+func (s *Server) authorized(apiKey string) bool {
+	return apiKey != "" && apiKey == s.ApiKey
+}
+
+func (s *Server) serveList(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	rows := append([]awin.DataFeedListRow{}, s.rows...)
+	s.mutex.Unlock()
+
+	body, err := encodeFeedList(rows)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.write(w, r, s.fault(func(f *Fault) bool { return f.List }), body)
+}
+
+func (s *Server) serveFeed(w http.ResponseWriter, r *http.Request, feedUrl *awin.DataFeedURL) {
+	if len(feedUrl.FeedIds) == 0 {
+		http.Error(w, "no feed ids", http.StatusBadRequest)
+		return
+	}
+	if feedUrl.Language == "" {
+		http.Error(w, "no language", http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	var entries []awin.DataFeedEntry
+	for _, feedId := range feedUrl.FeedIds {
+		feed, ok := s.feeds[feedId]
+		if !ok {
+			s.mutex.Unlock()
+			http.Error(w, fmt.Sprintf("unknown feed id '%s'", feedId), http.StatusNotFound)
+			return
+		}
+		entries = append(entries, feed...)
+	}
+	s.mutex.Unlock()
+
+	if err := validateColumns(feedUrl.Columns, entries); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	body, err := encodeFeed(entries, feedUrl)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	fault := s.fault(func(f *Fault) bool {
+		if f.List {
+			return false
+		}
+		for _, feedId := range feedUrl.FeedIds {
+			if f.FeedId == "" || f.FeedId == feedId {
+				return true
+			}
+		}
+		return false
+	})
+
+	// The ETag changes with the content, If-None-Match is answered with 304 like a real CDN would
+	hash := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(hash[:8]) + `"`
+	if fault == nil && r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+
+	s.write(w, r, fault, body)
+}
+
+// validateColumns accepts the known columns and the custom ones found in Extra of entries, like the vertical
+// specific columns of a feed
+func validateColumns(columns []awin.DataFeedColumn, entries []awin.DataFeedEntry) error {
+	custom := map[awin.DataFeedColumn]bool{}
+	for _, column := range extraColumns(entries) {
+		custom[column] = true
+	}
+
+	var known []awin.DataFeedColumn
+	for _, column := range columns {
+		if !custom[column] {
+			known = append(known, column)
+		}
+	}
+	return awin.ValidateDataFeedColumns(known)
+}
+
+// fault returns the first fault matching a request and counts it as applied, nil if none matches
+func (s *Server) fault(matches func(f *Fault) bool) *Fault {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, f := range s.faults {
+		if (f.Times == 0 || f.applied < f.Times) && matches(f) {
+			f.applied++
+			return f
+		}
+	}
+	return nil
+}
+
+// write sends body, or the error of fault, slowed down and truncated as fault demands
+func (s *Server) write(w http.ResponseWriter, r *http.Request, fault *Fault, body []byte) {
+	if fault == nil {
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		_, _ = w.Write(body)
+		return
+	}
+
+	for key, values := range fault.Header {
+		w.Header()[key] = values
+	}
+	if fault.StatusCode != 0 {
+		w.WriteHeader(fault.StatusCode)
+		_, _ = w.Write([]byte(fault.Body))
+		return
+	}
+
+	// The announced length stays the full body, the client sees a truncated download
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	if fault.TruncateAfter > 0 && fault.TruncateAfter < len(body) {
+		body = body[:fault.TruncateAfter]
+	}
+
+	flusher, _ := w.(http.Flusher)
+	for len(body) > 0 {
+		n := chunkSize
+		if n > len(body) {
+			n = len(body)
+		}
+		if fault.Delay > 0 {
+			select {
+			case <-time.After(fault.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if _, err := w.Write(body[:n]); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		body = body[n:]
+	}
+}
@@ -0,0 +1,109 @@
+package awinvalidate
+
+import (
+	"github.com/matthiasbruns/awin-go/awin"
+	"net/url"
+	"strings"
+)
+
+const (
+	// RuleRequired Name of the Required rule
+	RuleRequired = "required"
+	// RuleUrl Name of the Url rule
+	RuleUrl = "url"
+	// RulePrice Name of the Price rule
+	RulePrice = "price"
+	// RuleCurrency Name of the Currency rule
+	RuleCurrency = "currency"
+	// RuleGtin Name of the Gtin rule
+	RuleGtin = "gtin"
+)
+
+// currencyCodes holds the active ISO 4217 currency codes
+var currencyCodes = map[string]bool{}
+
+func init() {
+	for _, code := range strings.Fields(`
+		AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BRL BSD BTN BWP BYN BZD CAD CDF
+		CHF CLP CNY COP CRC CUP CVE CZK DJF DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD
+		HNL HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD LSL LYD
+		MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MYR MZN NAD NGN NIO NOK NPR NZD OMR PAB PEN PGK PHP PKR PLN
+		PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD SHP SLE SLL SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP
+		TRY TTD TWD TZS UAH UGX USD UYU UZS VES VND VUV WST XAF XCD XCG XOF XPF YER ZAR ZMW ZWG ZWL`) {
+		currencyCodes[code] = true
+	}
+}
+
+// DefaultRules
+// / Returns the rules a product needs to be listed: a deep link, image and price, well formed urls, non negative
+// / prices, an ISO 4217 currency and valid check digits of the global identifiers.
+func DefaultRules() []Rule {
+	return []Rule{
+		Required(awin.ColumnAwDeepLink, awin.ColumnMerchantImageUrl, awin.ColumnSearchPrice),
+		Url(awin.ColumnAwDeepLink, awin.ColumnMerchantImageUrl),
+		Price(awin.ColumnSearchPrice, awin.ColumnStorePrice, awin.ColumnRrpPrice, awin.ColumnDeliveryCost),
+		Currency(awin.ColumnCurrency),
+		Gtin(),
+	}
+}
+
+// Required
+// / Reports columns that are empty or only contain white space.
+func Required(columns ...awin.DataFeedColumn) Rule {
+	return RuleFunc(func(entry *awin.DataFeedEntry) []Violation {
+		var violations []Violation
+		for _, column := range columns {
+			if value := entry.Value(column); strings.TrimSpace(value) == "" {
+				violations = append(violations, Violation{Rule: RuleRequired, Column: column, Value: value, Message: "is required"})
+			}
+		}
+		return violations
+	})
+}
+
+// Url
+// / Reports values of columns that are not absolute http or https urls with a host.
+func Url(columns ...awin.DataFeedColumn) Rule {
+	return ColumnRule(RuleUrl, "is not an absolute http(s) url", validUrl, columns...)
+}
+
+// Price
+// / Reports values of columns that are not non negative decimal numbers, see awin.ParseDecimal.
+func Price(columns ...awin.DataFeedColumn) Rule {
+	return ColumnRule(RulePrice, "is not a non negative number", validPrice, columns...)
+}
+
+// Currency
+// / Reports values of columns that are not active ISO 4217 currency codes.
+func Currency(columns ...awin.DataFeedColumn) Rule {
+	return ColumnRule(RuleCurrency, "is not an ISO 4217 currency code", func(value string) bool {
+		return currencyCodes[strings.TrimSpace(value)]
+	}, columns...)
+}
+
+// Gtin
+// / Reports ean, upc and product_GTIN values that are not GTIN-8, -12, -13 or -14 numbers with a valid check digit
+// / and isbn values that are no valid ISBN-10 or ISBN-13, see awin.ParseGtin and awin.ParseIsbn.
+func Gtin() Rule {
+	gtin := ColumnRule(RuleGtin, "has no valid GTIN check digit", func(value string) bool {
+		_, err := awin.ParseGtin(value)
+		return err == nil
+	}, awin.ColumnEan, awin.ColumnUpc, awin.ColumnProductGtin)
+	isbn := ColumnRule(RuleGtin, "is no valid ISBN", func(value string) bool {
+		_, err := awin.ParseIsbn(value)
+		return err == nil
+	}, awin.ColumnIsbn)
+	return RuleFunc(func(entry *awin.DataFeedEntry) []Violation {
+		return append(gtin.Check(entry), isbn.Check(entry)...)
+	})
+}
+
+func validUrl(value string) bool {
+	u, err := url.Parse(strings.TrimSpace(value))
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func validPrice(value string) bool {
+	price, err := awin.ParseDecimal(value)
+	return err == nil && price.Unscaled >= 0
+}
@@ -0,0 +1,164 @@
+// Package awinvalidate checks data feed entries against rules, e.g. to reject products a catalog cannot list.
+package awinvalidate
+
+import (
+	"fmt"
+	"github.com/matthiasbruns/awin-go/awin"
+	"sort"
+)
+
+// Violation
+// / A value of an entry that breaks a rule.
+// / Rule The name of the rule, e.g. RuleRequired
+// / Column The column of the value, empty for rules about the entry as a whole
+// / Value The offending value
+// / Message Describes what is wrong with the value
+type Violation struct {
+	Rule    string
+	Column  awin.DataFeedColumn
+	Value   string
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Column == "" {
+		return fmt.Sprintf("%s: %s", v.Rule, v.Message)
+	}
+	return fmt.Sprintf("%s: %s %q %s", v.Rule, v.Column, v.Value, v.Message)
+}
+
+// Rule
+// / Checks a single entry and returns its violations, nil if the entry is valid.
+type Rule interface {
+	Check(entry *awin.DataFeedEntry) []Violation
+}
+
+// RuleFunc
+// / Adapter to use a function as Rule.
+type RuleFunc func(entry *awin.DataFeedEntry) []Violation
+
+func (f RuleFunc) Check(entry *awin.DataFeedEntry) []Violation {
+	return f(entry)
+}
+
+// ColumnRule
+// / Returns a rule named name that reports every non empty value of columns for which valid returns false,
+// / message describes the violation. The base for custom rules about single values.
+func ColumnRule(name string, message string, valid func(value string) bool, columns ...awin.DataFeedColumn) Rule {
+	return RuleFunc(func(entry *awin.DataFeedEntry) []Violation {
+		var violations []Violation
+		for _, column := range columns {
+			if value := entry.Value(column); value != "" && !valid(value) {
+				violations = append(violations, Violation{Rule: name, Column: column, Value: value, Message: message})
+			}
+		}
+		return violations
+	})
+}
+
+// Validator
+// / Checks entries against a set of rules, safe for concurrent use once all rules are registered.
+type Validator struct {
+	rules []Rule
+}
+
+// NewValidator
+// / Returns a Validator checking the given rules, DefaultRules if none are given.
+func NewValidator(rules ...Rule) *Validator {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	return &Validator{rules: rules}
+}
+
+// Register
+// / Adds custom rules, they are checked after the existing ones.
+func (v *Validator) Register(rules ...Rule) {
+	v.rules = append(v.rules, rules...)
+}
+
+// Validate
+// / Returns the violations of all rules in the order of the rules, nil if the entry is valid.
+func (v *Validator) Validate(entry *awin.DataFeedEntry) []Violation {
+	var violations []Violation
+	for _, rule := range v.rules {
+		violations = append(violations, rule.Check(entry)...)
+	}
+	return violations
+}
+
+// InvalidEntry
+// / An entry with at least one violation.
+// / Index The position of the entry in the feed, starting at 0
+type InvalidEntry struct {
+	Index      int
+	Entry      awin.DataFeedEntry
+	Violations []Violation
+}
+
+// FeedStats
+// / Aggregated violations of a feed.
+// / Entries The checked entries
+// / Invalid The entries with at least one violation
+// / Rules The violations by rule name
+// / Columns The violations by column, violations without a column are not counted
+type FeedStats struct {
+	Entries int
+	Invalid int
+	Rules   map[string]int
+	Columns map[awin.DataFeedColumn]int
+}
+
+// InvalidRatio
+// / Returns the share of invalid entries between 0 and 1, 0 for empty feeds.
+func (s FeedStats) InvalidRatio() float64 {
+	if s.Entries == 0 {
+		return 0
+	}
+	return float64(s.Invalid) / float64(s.Entries)
+}
+
+// RuleNames
+// / Returns the names of the violated rules sorted by their number of violations, most frequent first.
+func (s FeedStats) RuleNames() []string {
+	names := make([]string, 0, len(s.Rules))
+	for name := range s.Rules {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if s.Rules[names[i]] != s.Rules[names[j]] {
+			return s.Rules[names[i]] > s.Rules[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// ValidateFeed
+// / Checks all entries of a feed with constant memory, e.g. a DataFeedReader, and returns the aggregated stats.
+// / emit is called with every invalid entry and may be nil. Errors of entries and emit stop the validation.
+func (v *Validator) ValidateFeed(entries awin.EntryIterator, emit func(invalid InvalidEntry) error) (*FeedStats, error) {
+	stats := &FeedStats{Rules: map[string]int{}, Columns: map[awin.DataFeedColumn]int{}}
+	for entries.Next() {
+		entry := entries.Entry()
+		violations := v.Validate(&entry)
+		stats.Entries++
+		if len(violations) == 0 {
+			continue
+		}
+
+		stats.Invalid++
+		for _, violation := range violations {
+			stats.Rules[violation.Rule]++
+			if violation.Column != "" {
+				stats.Columns[violation.Column]++
+			}
+		}
+		if emit != nil {
+			if err := emit(InvalidEntry{Index: stats.Entries - 1, Entry: entry, Violations: violations}); err != nil {
+				return stats, err
+			}
+		}
+	}
+	return stats, entries.Err()
+}
@@ -0,0 +1,253 @@
+package awin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheFileSuffix marks completely downloaded bodies, partial ones are written to temporary files first
+const cacheFileSuffix = ".body"
+
+// cacheTempFileSuffix marks bodies that are still downloading, or were left over by an interrupted process
+const cacheTempFileSuffix = ".tmp"
+
+// staleTempFileAge is the time after which a temporary file that is no longer written to is removed
+const staleTempFileAge = time.Hour
+
+// DiskCache
+// / Stores raw response bodies of feed downloads and the feed list on disk, compressed feeds stay compressed.
+// / Entries are keyed by the normalized request url, the api key only enters the key as part of a sha256 hash.
+// / Share one DiskCache across clients, it is safe for concurrent use.
+type DiskCache struct {
+	dir      string
+	ttl      time.Duration
+	maxBytes int64
+	mutex    sync.Mutex
+}
+
+// NewDiskCache
+// / Returns a cache storing bodies in dir, which is created if missing.
+// / ttl Entries older than ttl are downloaded again, values below 1 keep entries until they are evicted
+// / maxBytes Oldest entries are evicted once all entries exceed maxBytes, values below 1 disable the limit
+func NewDiskCache(dir string, ttl time.Duration, maxBytes int64) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir, ttl: ttl, maxBytes: maxBytes}, nil
+}
+
+// Clear
+// / Removes all entries.
+func (c *DiskCache) Clear() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	files, err := c.files()
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Remove(filepath.Join(c.dir, file.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Prune
+// / Removes expired entries and evicts the oldest ones until the cache fits into maxBytes. Temporary files of
+// / downloads that were interrupted, e.g. by a crash, are removed once they have not been written to for an hour.
+// / Called after every stored download, call it manually to clean up a cache that is no longer written to.
+func (c *DiskCache) Prune() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.prune()
+}
+
+func (c *DiskCache) prune() error {
+	if err := c.removeStaleTempFiles(); err != nil {
+		return err
+	}
+
+	files, err := c.files()
+	if err != nil {
+		return err
+	}
+
+	// Oldest first
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime().Before(files[j].ModTime()) })
+
+	var size int64
+	for _, file := range files {
+		size += file.Size()
+	}
+
+	for _, file := range files {
+		if !c.expired(file) && (c.maxBytes < 1 || size <= c.maxBytes) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, file.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		size -= file.Size()
+	}
+	return nil
+}
+
+func (c *DiskCache) files() ([]os.FileInfo, error) {
+	entries, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []os.FileInfo
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), cacheFileSuffix) {
+			files = append(files, entry)
+		}
+	}
+	return files, nil
+}
+
+// removeStaleTempFiles removes temporary files that are no longer written to, running downloads keep theirs
+func (c *DiskCache) removeStaleTempFiles() error {
+	entries, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), cacheTempFileSuffix) || time.Since(entry.ModTime()) < staleTempFileAge {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, entry.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *DiskCache) expired(file os.FileInfo) bool {
+	return c.ttl > 0 && time.Since(file.ModTime()) > c.ttl
+}
+
+func (c *DiskCache) path(requestUrl string) string {
+	return filepath.Join(c.dir, cacheKey(requestUrl)+cacheFileSuffix)
+}
+
+// get returns the stored body of requestUrl, false if there is none or it expired
+func (c *DiskCache) get(requestUrl string) (io.ReadCloser, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	path := c.path(requestUrl)
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+
+	info, err := file.Stat()
+	if err != nil || c.expired(info) {
+		file.Close()
+		os.Remove(path)
+		return nil, false
+	}
+	return file, true
+}
+
+// tee returns body copying everything read to a temporary file, which is stored for requestUrl once body has been
+// read until EOF. Bodies closed before their end are discarded.
+func (c *DiskCache) tee(requestUrl string, body io.ReadCloser) io.ReadCloser {
+	file, err := ioutil.TempFile(c.dir, "download-*"+cacheTempFileSuffix)
+	if err != nil {
+		return body
+	}
+	return &cachingBody{ReadCloser: body, cache: c, path: c.path(requestUrl), file: file}
+}
+
+// store moves the completely downloaded temporary file to path
+func (c *DiskCache) store(temp string, path string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// The modification time is the time the entry was stored
+	now := time.Now()
+	if err := os.Chtimes(temp, now, now); err != nil {
+		return err
+	}
+	if err := os.Rename(temp, path); err != nil {
+		return err
+	}
+	return c.prune()
+}
+
+// cachingBody writes the body to file while it is read and stores it when EOF is reached
+type cachingBody struct {
+	io.ReadCloser
+	cache *DiskCache
+	path  string
+	file  *os.File
+}
+
+func (b *cachingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.file != nil && n > 0 {
+		if _, writeErr := b.file.Write(p[:n]); writeErr != nil {
+			// Caching is best effort, the download continues without it
+			b.discard()
+		}
+	}
+	if b.file != nil && err == io.EOF {
+		temp := b.file.Name()
+		closeErr := b.file.Close()
+		b.file = nil
+		if closeErr != nil || b.cache.store(temp, b.path) != nil {
+			os.Remove(temp)
+		}
+	}
+	return n, err
+}
+
+func (b *cachingBody) Close() error {
+	b.discard()
+	return b.ReadCloser.Close()
+}
+
+func (b *cachingBody) discard() {
+	if b.file != nil {
+		b.file.Close()
+		os.Remove(b.file.Name())
+		b.file = nil
+	}
+}
+
+// cacheable reports whether a request with header may be served from the cache, conditional requests and
+// requests with Cache-Control: no-cache always reach Awin
+func cacheable(header http.Header) bool {
+	return header.Get("If-None-Match") == "" && header.Get("If-Modified-Since") == "" &&
+		!strings.Contains(strings.ToLower(header.Get("Cache-Control")), "no-cache")
+}
+
+// cacheKey hashes the normalized url, scheme and host are lower case and trailing slashes are ignored
+func cacheKey(requestUrl string) string {
+	normalized := requestUrl
+	if parsed, err := url.Parse(requestUrl); err == nil {
+		parsed.Scheme = strings.ToLower(parsed.Scheme)
+		parsed.Host = strings.ToLower(parsed.Host)
+		parsed.Fragment = ""
+		normalized = strings.TrimRight(parsed.String(), "/")
+	}
+
+	hash := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(hash[:])
+}
@@ -3,44 +3,43 @@
 package awin
 
 import (
-	"bytes"
-	"compress/gzip"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/gocarina/gocsv"
 	"io"
-	"io/ioutil"
 	"net/http"
-	"strings"
+	"sync"
+	"time"
 )
 
 // Constants for url building
 const (
-	baseUrl = "https://productdata.awin.com"
+	defaultBaseUrl = "https://productdata.awin.com"
 
 	/// Example https://productdata.awin.com/datafeed/list/apikey/18a4da1c74680374b05647897c678f94
 	dataFeedListUrl = "%s/datafeed/list/apikey/%s"
-
-	/// Example https://productdata.awin.com/datafeed/download/apikey/18a4da1c74680374b05647897c678f94/language/de/fid/123,456/columns/aw_deep_link,product_name,aw_product_id,data_feed_id/format/csv/delimiter/%2C/compression/gzip/adultcontent/1/
-	dataFeedUrl = "%s/datafeed/download/apikey/%s/language/%s/fid/%s/columns/%s/format/csv/delimiter/%s/compression/gzip/adultcontent/%d/"
 )
 
 var (
-	dataFeedColumns = []string{
-		"aw_deep_link", "product_name", "aw_product_id", "merchant_product_id", "merchant_image_url", "description",
-		"merchant_category", "search_price", "merchant_name", "merchant_id", "category_name", "category_id",
-		"aw_image_url", "currency", "store_price", "delivery_cost", "merchant_deep_link", "language", "last_updated",
-		"display_price", "data_feed_id", "brand_name", "brand_id", "colour", "product_short_description",
-		"specifications", "condition", "product_model", "model_number", "dimensions", "keywords", "promotional_text",
-		"product_type", "commission_group", "merchant_product_category_path", "merchant_product_second_category",
-		"merchant_product_third_category", "rrp_price", "saving", "savings_percent", "base_price", "base_price_amount",
-		"base_price_text", "product_price_old", "delivery_restrictions", "delivery_weight", "warranty",
-		"terms_of_contract", "delivery_time", "in_stock", "stock_quantity", "valid_from", "valid_to", "is_for_sale",
-		"web_offer", "pre_order", "stock_status", "size_stock_status", "size_stock_amount", "merchant_thumb_url",
-		"large_image", "alternate_image", "aw_thumb_url", "alternate_image_two", "alternate_image_three",
-		"alternate_image_four", "reviews", "average_rating", "rating", "number_available", "custom_1", "custom_2",
-		"custom_3", "custom_4", "custom_5", "custom_6", "custom_7", "custom_8", "custom_9", "ean", "isbn", "upc",
-		"mpn", "parent_product_id", "product_GTIN", "basket_link",
+	dataFeedColumns = []DataFeedColumn{
+		ColumnAwDeepLink, ColumnProductName, ColumnAwProductId, ColumnMerchantProductId, ColumnMerchantImageUrl,
+		ColumnDescription, ColumnMerchantCategory, ColumnSearchPrice, ColumnMerchantName, ColumnMerchantId,
+		ColumnCategoryName, ColumnCategoryId, ColumnAwImageUrl, ColumnCurrency, ColumnStorePrice, ColumnDeliveryCost,
+		ColumnMerchantDeepLink, ColumnLanguage, ColumnLastUpdated, ColumnDisplayPrice, ColumnDataFeedId, ColumnBrandName,
+		ColumnBrandId, ColumnColour, ColumnProductShortDescription, ColumnSpecifications, ColumnCondition,
+		ColumnProductModel, ColumnModelNumber, ColumnDimensions, ColumnKeywords, ColumnPromotionalText, ColumnProductType,
+		ColumnCommissionGroup, ColumnMerchantProductCategoryPath, ColumnMerchantProductSecondCategory,
+		ColumnMerchantProductThirdCategory, ColumnRrpPrice, ColumnSaving, ColumnSavingsPercent, ColumnBasePrice,
+		ColumnBasePriceAmount, ColumnBasePriceText, ColumnProductPriceOld, ColumnDeliveryRestrictions,
+		ColumnDeliveryWeight, ColumnWarranty, ColumnTermsOfContract, ColumnDeliveryTime, ColumnInStock,
+		ColumnStockQuantity, ColumnValidFrom, ColumnValidTo, ColumnIsForSale, ColumnWebOffer, ColumnPreOrder,
+		ColumnStockStatus, ColumnSizeStockStatus, ColumnSizeStockAmount, ColumnMerchantThumbUrl, ColumnLargeImage,
+		ColumnAlternateImage, ColumnAwThumbUrl, ColumnAlternateImageTwo, ColumnAlternateImageThree,
+		ColumnAlternateImageFour, ColumnReviews, ColumnAverageRating, ColumnRating, ColumnNumberAvailable, ColumnCustom1,
+		ColumnCustom2, ColumnCustom3, ColumnCustom4, ColumnCustom5, ColumnCustom6, ColumnCustom7, ColumnCustom8,
+		ColumnCustom9, ColumnEan, ColumnIsbn, ColumnUpc, ColumnMpn, ColumnParentProductId, ColumnProductGtin,
+		ColumnBasketLink,
 	}
 
 	defaultDataFeedColumnsParam string
@@ -48,82 +47,523 @@ var (
 
 func init() {
 	// Generate default column params on init to reduce loops when doing the requests
-	defaultDataFeedColumnsParam = strings.Join(dataFeedColumns, ",")
+	defaultDataFeedColumnsParam = joinDataFeedColumns(dataFeedColumns)
 }
 
 // DataFeedOptions
 // / FeedIds The string slice of all publisher feed ids
 // / Language ISO 3166-1 alpha-2 – two-letter country codes e.g. de, en
 // / ShowAdultContent true to include adult content
+// / Columns The columns to download, only these DataFeedEntry fields get populated. All columns are requested if empty.
+// / Custom columns like Fashion:size may be requested too, their values are kept in DataFeedEntry.Extra
+// / Format The output format Awin generates, all formats are decoded into DataFeedEntry. FormatCsv if empty
+// / Delimiter The column delimiter of csv feeds, DelimiterComma if 0. Pick pipe or tab for feeds with commas in their texts
+// / Compression The compression Awin applies, CompressionGzip if empty. Downloads detect the compression on their own
 type DataFeedOptions struct {
 	FeedIds          []string
 	Language         string
 	ShowAdultContent bool
+	Columns          []DataFeedColumn
+	Format           DataFeedFormat
+	Delimiter        DataFeedDelimiter
+	Compression      DataFeedCompression
+}
+
+// dataFeedRequest describes a single feed download and how its response is decoded
+type dataFeedRequest struct {
+	url       string
+	feedIds   []string
+	format    DataFeedFormat
+	delimiter DataFeedDelimiter
+	columns   []DataFeedColumn
+	header    http.Header
 }
 
 // AwinClient
 // / client that takes over the communication with the Awin endpoints as well as parsing the response csv data into structs.
 // / apiKey You can get the download API key from a standard feed download as given by Create-a-Feed. You can also get the full download link including the relevant API key to access this file from the Create-a-Feed section in the interface (Awin interface --> Toolbox --> Create-a-Feed).
 type AwinClient struct {
-	client *http.Client
-	apiKey string
+	client           *http.Client
+	apiKey           string
+	baseUrl          string
+	userAgent        string
+	timeout          time.Duration
+	logger           Logger
+	metrics          Metrics
+	tracer           Tracer
+	retryPolicy      *RetryPolicy
+	limiter          Limiter
+	cache            *DiskCache
+	lenient          *lenientDecoding
+	schemaDrift      SchemaDriftMode
+	progress         ProgressObserver
+	progressInterval time.Duration
+	defaultOptions   DataFeedOptions
+}
+
+// SetRetryPolicy
+// / Enables retries of transient failures for the feed list and feed downloads, see RetryPolicy.
+// / Streams are only retried until the csv header is read, failures while reading entries are returned by Err.
+func (c *AwinClient) SetRetryPolicy(policy RetryPolicy) {
+	c.retryPolicy = &policy
+}
+
+// SetLimiter
+// / Makes every request wait for the limiter, e.g. a RateLimiter shared by all importers using the same api key.
+// / Downloads count as in flight until their body is closed.
+func (c *AwinClient) SetLimiter(limiter Limiter) {
+	c.limiter = limiter
+}
+
+// SetCache
+// / Serves repeated downloads of the same feed or the feed list from cache instead of Awin, nil disables caching.
+// / Only complete downloads are stored, conditional requests of RefreshDataFeeds bypass the cache.
+func (c *AwinClient) SetCache(cache *DiskCache) {
+	c.cache = cache
+}
+
+// SetProgressObserver
+// / Reports the progress of every feed download to observer: once the response arrives, at most once per interval
+// / while entries are decoded and a last time with Done set. An interval of 0 reports every entry, nil disables reports.
+// / Retried downloads start again from zero.
+func (c *AwinClient) SetProgressObserver(observer ProgressObserver, interval time.Duration) {
+	c.progress = observer
+	c.progressInterval = interval
 }
 
 func (c AwinClient) FetchDataFeedList() (*[]DataFeedListRow, error) {
-	// Get list of joined and not joined publishers
-	resp, err := c.client.Get(fmt.Sprintf(dataFeedListUrl, baseUrl, c.apiKey))
+	return c.FetchDataFeedListWithContext(context.Background())
+}
+
+// FetchDataFeedListWithContext
+// / Same as FetchDataFeedList, cancelling ctx aborts the request as well as the csv decoding and returns ctx.Err().
+func (c AwinClient) FetchDataFeedListWithContext(ctx context.Context) (*[]DataFeedListRow, error) {
+	return c.fetchDataFeedList(ctx, nil)
+}
+
+// fetchDataFeedList downloads the feed list sending header with every attempt
+func (c AwinClient) fetchDataFeedList(ctx context.Context, header http.Header) (*[]DataFeedListRow, error) {
+	ctx, span := c.tracer.Start(ctx, SpanFetchDataFeedList)
+	start := time.Now()
+	var rows *[]DataFeedListRow
+	err := c.retry(ctx, EndpointFeedList, func() error {
+		// Get list of joined and not joined publishers
+		attemptStart := time.Now()
+		resp, err := c.get(ctx, EndpointFeedList, fmt.Sprintf(dataFeedListUrl, c.baseUrl, c.apiKey), header)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		counter := &countingReader{r: contextReader{ctx: ctx, r: resp.Body}}
+		rows, err = parseCSVToDataFeedRow(counter)
+
+		download := DownloadMetrics{Endpoint: EndpointFeedList, Bytes: counter.n, Duration: time.Since(attemptStart), Err: err, Complete: err == nil}
+		if rows != nil {
+			download.Rows = len(*rows)
+		}
+		c.metrics.ObserveDownload(download)
+		return err
+	})
 	if err != nil {
+		err = contextError(ctx, err)
+		c.logger.Error("awin feed list download failed", "error", err, "duration", time.Since(start))
+		span.End(err)
 		return nil, err
 	}
 
-	return parseCSVToDataFeedRow(resp.Body)
+	c.logger.Info("awin feed list downloaded", "rows", len(*rows), "duration", time.Since(start))
+	span.SetAttribute("awin.rows", len(*rows))
+	span.End(nil)
+	return rows, nil
 }
 
 func (c AwinClient) FetchDataFeed(options *DataFeedOptions) (*[]DataFeedEntry, error) {
-	// Get product list of data feed
-	showAdult := 0
-	if options.ShowAdultContent {
-		showAdult = 1
-	}
+	return c.FetchDataFeedWithContext(context.Background(), options)
+}
 
-	url := fmt.Sprintf(dataFeedUrl, baseUrl, c.apiKey, options.Language, strings.Join(options.FeedIds, ","), defaultDataFeedColumnsParam, ",", showAdult)
+// FetchDataFeedWithContext
+// / Same as FetchDataFeed, cancelling ctx aborts the download as well as the csv decoding and returns ctx.Err().
+func (c AwinClient) FetchDataFeedWithContext(ctx context.Context, options *DataFeedOptions) (*[]DataFeedEntry, error) {
+	options = c.dataFeedOptions(options)
+	request, err := c.dataFeedRequest(options)
+	if err != nil {
+		return nil, err
+	}
 
-	return c.FetchDataFeedFromUrl(url)
+	return c.fetchDataFeed(ctx, request)
 }
 
 func (c AwinClient) FetchDataFeedFromUrl(url string) (*[]DataFeedEntry, error) {
-	request, err := http.NewRequest("GET", url, nil)
+	return c.FetchDataFeedFromUrlWithContext(context.Background(), url)
+}
+
+// FetchDataFeedFromUrlWithContext
+// / Same as FetchDataFeedFromUrl, cancelling ctx aborts the download as well as the csv decoding and returns ctx.Err().
+func (c AwinClient) FetchDataFeedFromUrlWithContext(ctx context.Context, url string) (*[]DataFeedEntry, error) {
+	feedUrl, err := ParseDataFeedURL(url)
 	if err != nil {
 		return nil, err
 	}
-	request.Header.Set("Accept-Encoding", "gzip")
 
-	resp, err := c.client.Do(request)
+	return c.fetchDataFeed(ctx, feedUrl.request(url))
+}
+
+// FetchDataFeedWithReport
+// / Same as FetchDataFeed, also returning the rows skipped by lenient decoding, see WithLenientDecoding.
+// / The report is returned on errors as well, e.g. with the *RowErrorRatioError of a feed with too many invalid rows.
+func (c AwinClient) FetchDataFeedWithReport(options *DataFeedOptions) (*[]DataFeedEntry, DecodeReport, error) {
+	return c.FetchDataFeedWithReportWithContext(context.Background(), options)
+}
+
+// FetchDataFeedWithReportWithContext
+// / Same as FetchDataFeedWithReport, cancelling ctx aborts the download as well as the csv decoding and returns ctx.Err().
+func (c AwinClient) FetchDataFeedWithReportWithContext(ctx context.Context, options *DataFeedOptions) (*[]DataFeedEntry, DecodeReport, error) {
+	options = c.dataFeedOptions(options)
+	request, err := c.dataFeedRequest(options)
+	if err != nil {
+		return nil, DecodeReport{}, err
+	}
+
+	download, err := c.fetchDataFeedDownload(ctx, request)
+	return download.entries, download.report, err
+}
+
+// StreamDataFeed
+// / Same as FetchDataFeed, but returns a DataFeedReader that decodes the entries while the feed is downloaded.
+// / The caller has to close the returned reader.
+func (c AwinClient) StreamDataFeed(options *DataFeedOptions) (*DataFeedReader, error) {
+	return c.StreamDataFeedWithContext(context.Background(), options)
+}
+
+// StreamDataFeedWithContext
+// / Same as StreamDataFeed, once ctx is cancelled the reader stops and Err returns ctx.Err().
+func (c AwinClient) StreamDataFeedWithContext(ctx context.Context, options *DataFeedOptions) (*DataFeedReader, error) {
+	options = c.dataFeedOptions(options)
+	request, err := c.dataFeedRequest(options)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	plainResponse, err := ioutil.ReadAll(resp.Body)
+	return c.streamDataFeed(ctx, request)
+}
+
+// StreamDataFeedFromUrl
+// / Same as FetchDataFeedFromUrl, but returns a DataFeedReader that decodes the entries straight from the download stream.
+// / The caller has to close the returned reader.
+func (c AwinClient) StreamDataFeedFromUrl(url string) (*DataFeedReader, error) {
+	return c.StreamDataFeedFromUrlWithContext(context.Background(), url)
+}
+
+// StreamDataFeedFromUrlWithContext
+// / Same as StreamDataFeedFromUrl, once ctx is cancelled the reader stops and Err returns ctx.Err().
+func (c AwinClient) StreamDataFeedFromUrlWithContext(ctx context.Context, url string) (*DataFeedReader, error) {
+	feedUrl, err := ParseDataFeedURL(url)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != 200 {
-		return nil, errors.New(string(plainResponse))
+	return c.streamDataFeed(ctx, feedUrl.request(url))
+}
+
+// fetchDataFeed downloads the feed and collects all its entries
+// truncated downloads are retried as a whole, nothing has been returned to the caller yet
+func (c AwinClient) fetchDataFeed(ctx context.Context, request dataFeedRequest) (*[]DataFeedEntry, error) {
+	download, err := c.fetchDataFeedDownload(ctx, request)
+	return download.entries, err
+}
+
+// dataFeedDownload is a collected feed with the bytes downloaded by all attempts, the last response header and
+// the decode report of the last attempt
+type dataFeedDownload struct {
+	entries *[]DataFeedEntry
+	bytes   int64
+	header  http.Header
+	report  DecodeReport
+}
+
+// fetchDataFeedDownload is fetchDataFeed also returning the details of the download
+func (c AwinClient) fetchDataFeedDownload(ctx context.Context, request dataFeedRequest) (dataFeedDownload, error) {
+	ctx, span := c.tracer.Start(ctx, SpanFetchDataFeed)
+	span.SetAttribute("awin.feed_ids", request.feedIds)
+
+	var download dataFeedDownload
+	err := c.retry(ctx, EndpointDataFeed, func() error {
+		reader, err := c.openDataFeed(ctx, request)
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+
+		download.entries, err = collectDataFeedEntries(reader)
+		download.bytes += reader.BytesRead()
+		download.header = reader.header
+		download.report = reader.Report()
+		return err
+	})
+	span.SetAttribute("awin.bytes", download.bytes)
+	if err != nil {
+		download.entries = nil
+		err = contextError(ctx, err)
+		c.logDownloadError(request, err)
+		span.End(failure(err))
+		return download, err
+	}
+
+	span.SetAttribute("awin.rows", len(*download.entries))
+	span.End(nil)
+	return download, nil
+}
+
+// streamDataFeed downloads the feed and returns a reader decoding it
+// the span ends once the returned reader is exhausted or closed
+func (c AwinClient) streamDataFeed(ctx context.Context, request dataFeedRequest) (*DataFeedReader, error) {
+	ctx, span := c.tracer.Start(ctx, SpanFetchDataFeed)
+	span.SetAttribute("awin.feed_ids", request.feedIds)
+
+	var reader *DataFeedReader
+	err := c.retry(ctx, EndpointDataFeed, func() error {
+		var err error
+		reader, err = c.openDataFeed(ctx, request)
+		return err
+	})
+	if err != nil {
+		c.logDownloadError(request, err)
+		span.End(failure(err))
+		return nil, err
+	}
+
+	finish := reader.onFinish
+	reader.onFinish = func(ended bool) {
+		finish(ended)
+		span.SetAttribute("awin.rows", reader.rows)
+		span.SetAttribute("awin.bytes", reader.BytesRead())
+		span.End(reader.err)
+	}
+	return reader, nil
+}
+
+// logDownloadError logs the final error of a feed download, 304 answers to conditional requests are no failure
+func (c AwinClient) logDownloadError(request dataFeedRequest, err error) {
+	if failure(err) == nil {
+		return
+	}
+	c.logger.Error("awin feed download failed", "url", redactUrl(request.url), "feed_ids", request.feedIds, "error", err)
+}
+
+// failure returns err unless it is the 304 answer to a conditional request
+func failure(err error) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	return err
+}
+
+// finishDataFeed logs and measures the outcome of a download attempt once its reader is exhausted or closed
+func (c AwinClient) finishDataFeed(request dataFeedRequest, start time.Time, reader *DataFeedReader, ended bool) {
+	url, bytes, duration := redactUrl(request.url), reader.BytesRead(), time.Since(start)
+	if report := reader.Report(); report.Skipped > 0 {
+		c.logger.Warn("awin feed rows skipped", "url", url, "rows", report.Rows, "skipped", report.Skipped, "error_ratio", report.ErrorRatio())
+	}
+	switch {
+	case reader.err != nil:
+		c.logger.Error("awin feed decode failed", "url", url, "rows", reader.rows, "bytes", bytes, "error", reader.err, "duration", duration)
+	case ended:
+		c.logger.Info("awin feed downloaded", "url", url, "rows", reader.rows, "bytes", bytes, "duration", duration)
+	default:
+		c.logger.Info("awin feed closed", "url", url, "rows", reader.rows, "bytes", bytes, "duration", duration)
 	}
 
+	c.metrics.ObserveDownload(DownloadMetrics{
+		Endpoint: EndpointDataFeed,
+		FeedIds:  request.feedIds,
+		Rows:     reader.rows,
+		Bytes:    bytes,
+		Duration: duration,
+		Err:      reader.err,
+		Complete: ended && reader.err == nil,
+	})
+}
+
+// openDataFeed sends a single request for the feed and reads up to the first entry
+func (c AwinClient) openDataFeed(ctx context.Context, request dataFeedRequest) (*DataFeedReader, error) {
+	header := http.Header{"Accept-Encoding": {"gzip"}}
+	for key, values := range request.header {
+		header[key] = values
+	}
+	start := time.Now()
+	resp, err := c.get(ctx, EndpointDataFeed, request.url, header)
 	if err != nil {
 		return nil, err
 	}
 
-	// gzip response
-	gzipReader, err := gzip.NewReader(bytes.NewReader(plainResponse))
+	counter := &countingReader{r: contextReader{ctx: ctx, r: resp.Body}}
+
+	body, err := decompress(resp.Header, counter)
+	if err != nil {
+		resp.Body.Close()
+		return nil, c.openDataFeedError(ctx, request, start, counter, err)
+	}
+
+	reader, err := newDataFeedReader(body, request.format, request.delimiter, request.columns, c.lenient)
 	if err != nil {
+		body.Close()
+		resp.Body.Close()
+		return nil, c.openDataFeedError(ctx, request, start, counter, err)
+	}
+	if drift := reader.drift; !drift.Empty() {
+		switch c.schemaDrift {
+		case SchemaDriftStrict:
+			body.Close()
+			resp.Body.Close()
+			return nil, c.openDataFeedError(ctx, request, start, counter, &SchemaDriftError{Drift: drift})
+		case SchemaDriftWarn:
+			c.logger.Warn("awin schema drift", "url", redactUrl(request.url), "feed_ids", request.feedIds, "unknown", drift.Unknown, "missing", drift.Missing)
+		}
+	}
+	reader.closer = multiCloser{body, resp.Body}
+	reader.header = resp.Header
+	reader.counter = counter
+	reader.onFinish = func(ended bool) {
+		c.finishDataFeed(request, start, reader, ended)
+	}
+
+	if c.progress != nil {
+		reader.progress = newProgressTracker(c.progress, c.progressInterval, request, resp.ContentLength, start)
+		reader.progress.counter = counter
+		reader.progress.report()
+	}
+
+	return reader, nil
+}
+
+// openDataFeedError measures a download attempt that failed before its first entry
+func (c AwinClient) openDataFeedError(ctx context.Context, request dataFeedRequest, start time.Time, counter *countingReader, err error) error {
+	err = contextError(ctx, err)
+	c.metrics.ObserveDownload(DownloadMetrics{
+		Endpoint: EndpointDataFeed,
+		FeedIds:  request.feedIds,
+		Bytes:    counter.n,
+		Duration: time.Since(start),
+		Err:      err,
+	})
+	return err
+}
+
+// get sends a GET request to url and returns the response if its status is 200, otherwise an *APIError
+func (c AwinClient) get(ctx context.Context, endpoint Endpoint, url string, header http.Header) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, redactError(err)
+	}
+	for key, values := range header {
+		request.Header[key] = values
+	}
+	if c.userAgent != "" {
+		request.Header.Set("User-Agent", c.userAgent)
+	}
+
+	useCache := c.cache != nil && cacheable(request.Header)
+	if useCache {
+		if body, ok := c.cache.get(url); ok {
+			c.logger.Debug("awin cache hit", "url", redactUrl(url))
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: body, ContentLength: -1}, nil
+		}
+	}
+
+	release := func() {}
+	if c.limiter != nil {
+		limiterRelease, err := c.limiter.Acquire(ctx)
+		if err != nil {
+			return nil, contextError(ctx, err)
+		}
+		var once sync.Once
+		release = func() { once.Do(limiterRelease) }
+	}
+
+	c.logger.Debug("awin request", "url", redactUrl(url))
+	start := time.Now()
+
+	resp, err := c.client.Do(request)
+	if err != nil {
+		release()
+		err = contextError(ctx, redactError(err))
+		c.metrics.ObserveRequest(endpoint, 0, time.Since(start))
+		c.logger.Warn("awin request failed", "url", redactUrl(url), "error", err, "duration", time.Since(start))
 		return nil, err
 	}
+	resp.Body = releaseOnClose{ReadCloser: resp.Body, release: release}
+	c.metrics.ObserveRequest(endpoint, resp.StatusCode, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		apiErr := newAPIError(url, resp)
+		if resp.StatusCode == http.StatusNotModified {
+			c.logger.Debug("awin response", "url", apiErr.URL, "status", resp.StatusCode, "duration", time.Since(start))
+		} else {
+			c.logger.Warn("awin request failed", "url", apiErr.URL, "status", resp.StatusCode, "error", apiErr, "duration", time.Since(start))
+		}
+		return nil, apiErr
+	}
+	c.logger.Debug("awin response", "url", redactUrl(url), "status", resp.StatusCode, "content_length", resp.ContentLength, "duration", time.Since(start))
 
-	return parseCSVToDataFeedEntry(gzipReader)
+	if useCache {
+		resp.Body = c.cache.tee(url, resp.Body)
+	}
+
+	return resp, nil
+}
+
+// dataFeedOptions fills the empty fields of options with the client defaults. ShowAdultContent cannot tell false
+// from unset, passed options keep their own.
+func (c AwinClient) dataFeedOptions(options *DataFeedOptions) *DataFeedOptions {
+	if options == nil {
+		defaults := c.defaultOptions
+		return &defaults
+	}
+
+	merged := *options
+	if len(merged.FeedIds) == 0 {
+		merged.FeedIds = c.defaultOptions.FeedIds
+	}
+	if merged.Language == "" {
+		merged.Language = c.defaultOptions.Language
+	}
+	if len(merged.Columns) == 0 {
+		merged.Columns = c.defaultOptions.Columns
+	}
+	if merged.Format == "" {
+		merged.Format = c.defaultOptions.Format
+	}
+	if merged.Delimiter == 0 {
+		merged.Delimiter = c.defaultOptions.Delimiter
+	}
+	if merged.Compression == "" {
+		merged.Compression = c.defaultOptions.Compression
+	}
+	return &merged
+}
+
+func (c AwinClient) dataFeedRequest(options *DataFeedOptions) (dataFeedRequest, error) {
+	if len(options.Columns) > 0 {
+		if err := ValidateDataFeedColumns(options.Columns); err != nil {
+			return dataFeedRequest{}, err
+		}
+	}
+
+	feedUrl := newDataFeedURL(c.baseUrl, c.apiKey, *options)
+	if feedUrl.Format == "" {
+		feedUrl.Format = FormatCsv
+	}
+	if feedUrl.Delimiter == 0 {
+		feedUrl.Delimiter = DelimiterComma
+	}
+	if err := feedUrl.validate(); err != nil {
+		return dataFeedRequest{}, err
+	}
+
+	return feedUrl.request(feedUrl.String()), nil
 }
 
 func parseCSVToDataFeedRow(r io.Reader) (*[]DataFeedListRow, error) {
@@ -136,24 +576,63 @@ func parseCSVToDataFeedRow(r io.Reader) (*[]DataFeedListRow, error) {
 	return &rows, nil
 }
 
-func parseCSVToDataFeedEntry(r io.Reader) (*[]DataFeedEntry, error) {
+func collectDataFeedEntries(reader *DataFeedReader) (*[]DataFeedEntry, error) {
 	var entries []DataFeedEntry
 
-	if err := gocsv.Unmarshal(r, &entries); err != nil {
+	for reader.Next() {
+		entries = append(entries, reader.Entry())
+	}
+	if err := reader.Err(); err != nil {
 		return nil, err
 	}
 
 	return &entries, nil
 }
 
+// contextReader stops reading with ctx.Err() as soon as ctx is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// contextError prefers ctx.Err() over err, so callers can compare against context.Canceled and context.DeadlineExceeded
+func contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// multiCloser closes all closers in order and returns the first error
+type multiCloser []io.Closer
+
+func (m multiCloser) Close() error {
+	var firstErr error
+	for _, c := range m {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// NewAwinClient
+// / Returns a new AwinClient using client for all requests, see New for more options.
 func NewAwinClient(apiKey string, client *http.Client) *AwinClient {
-	return &AwinClient{client: client, apiKey: apiKey}
+	return New(apiKey, WithHttpClient(client))
 }
 
-// NewAwinClientWithHzzp
-// / Returns a new NewAwinClientWithHzzp. Needs a http.Client passed from outside.
+// NewAwinClientWithHttp
+// / Returns a new AwinClient. Needs a http.Client passed from outside.
 // / client Required to be passed from the caller
 // / returns a new instance of AwinClient
 func NewAwinClientWithHttp(apiKey string, client *http.Client) *AwinClient {
-	return &AwinClient{client: client, apiKey: apiKey}
+	return New(apiKey, WithHttpClient(client))
 }
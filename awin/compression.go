@@ -0,0 +1,162 @@
+package awin
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// DataFeedCompression
+// / Compression of a data feed download.
+type DataFeedCompression string
+
+const (
+	CompressionGzip DataFeedCompression = "gzip"
+	CompressionZip  DataFeedCompression = "zip"
+	CompressionNone DataFeedCompression = "none"
+)
+
+// DataFeedDelimiter
+// / Column delimiter of csv data feeds.
+type DataFeedDelimiter rune
+
+const (
+	DelimiterComma DataFeedDelimiter = ','
+	DelimiterPipe  DataFeedDelimiter = '|'
+	DelimiterTab   DataFeedDelimiter = '\t'
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+
+	errEmptyZip = errors.New("zip data feed contains no file")
+)
+
+func (c DataFeedCompression) validate() error {
+	switch c {
+	case CompressionGzip, CompressionZip, CompressionNone:
+		return nil
+	}
+	return fmt.Errorf("unknown data feed compression '%s'", c)
+}
+
+func (d DataFeedDelimiter) validate() error {
+	switch d {
+	case DelimiterComma, DelimiterPipe, DelimiterTab:
+		return nil
+	}
+	return fmt.Errorf("unsupported data feed delimiter '%c'", d)
+}
+
+// urlParam returns the delimiter as url path segment, the comma is kept readable as Awin accepts it as is
+func (d DataFeedDelimiter) urlParam() string {
+	switch d {
+	case DelimiterPipe:
+		return "%7C"
+	case DelimiterTab:
+		return "%09"
+	default:
+		return ","
+	}
+}
+
+// detectDelimiter picks the candidate delimiter occurring most often in the header line of a csv feed
+func detectDelimiter(r *bufio.Reader) DataFeedDelimiter {
+	head, _ := r.Peek(4096)
+	if newline := bytes.IndexByte(head, '\n'); newline >= 0 {
+		head = head[:newline]
+	}
+
+	delimiter, count := DelimiterComma, 0
+	for _, candidate := range []DataFeedDelimiter{DelimiterComma, DelimiterPipe, DelimiterTab} {
+		if n := bytes.Count(head, []byte{byte(candidate)}); n > count {
+			delimiter, count = candidate, n
+		}
+	}
+	return delimiter
+}
+
+// decompress detects the compression of body from the magic bytes, falling back to the response headers, and
+// returns the decompressed content. Zip archives are spooled to a temporary file as they need random access.
+func decompress(header http.Header, body io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(body)
+	magic, _ := buffered.Peek(4)
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return gzip.NewReader(buffered)
+	case bytes.HasPrefix(magic, zipMagic):
+		return unzip(buffered)
+	case len(magic) == 0 && isCompressedResponse(header):
+		// Compressed responses are never empty, this is a truncated download
+		return nil, io.ErrUnexpectedEOF
+	default:
+		return ioutil.NopCloser(buffered), nil
+	}
+}
+
+func isCompressedResponse(header http.Header) bool {
+	encoding := strings.ToLower(header.Get("Content-Encoding"))
+	contentType := strings.ToLower(header.Get("Content-Type"))
+	return strings.Contains(encoding, "gzip") || strings.Contains(contentType, "gzip") || strings.Contains(contentType, "zip")
+}
+
+// unzip copies the archive to a temporary file and opens its first file, closing it removes the temporary file
+func unzip(r io.Reader) (io.ReadCloser, error) {
+	file, err := ioutil.TempFile("", "awin-feed-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	cleanup := func() {
+		file.Close()
+		os.Remove(file.Name())
+	}
+
+	size, err := io.Copy(file, r)
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(file, size)
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	for _, f := range archive.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		content, err := f.Open()
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+		return zipFileReader{ReadCloser: content, cleanup: cleanup}, nil
+	}
+
+	cleanup()
+	return nil, errEmptyZip
+}
+
+// zipFileReader removes the spooled archive once the file inside is closed
+type zipFileReader struct {
+	io.ReadCloser
+	cleanup func()
+}
+
+func (z zipFileReader) Close() error {
+	err := z.ReadCloser.Close()
+	z.cleanup()
+	return err
+}
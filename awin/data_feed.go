@@ -0,0 +1,118 @@
+package awin
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// listColumn is a header of the data feed list, it is no DataFeedColumn and cannot be requested from a feed
+type listColumn string
+
+// Columns of the data feed list that are parsed into DataFeed
+const (
+	listColumnMembershipStatus listColumn = "Membership Status"
+	listColumnLastImported     listColumn = "Last Imported"
+	listColumnLastChecked      listColumn = "Last Checked"
+	listColumnNoOfProducts     listColumn = "No of products"
+)
+
+// MembershipStatus
+// / Status of the publisher membership for the advertiser of a data feed.
+type MembershipStatus int
+
+const (
+	MembershipStatusUnknown MembershipStatus = iota
+	MembershipStatusActive
+	MembershipStatusNotJoined
+	MembershipStatusPending
+	MembershipStatusSuspended
+	MembershipStatusRejected
+)
+
+var membershipStatusNames = map[MembershipStatus]string{
+	MembershipStatusUnknown:   "unknown",
+	MembershipStatusActive:    "active",
+	MembershipStatusNotJoined: "not joined",
+	MembershipStatusPending:   "pending",
+	MembershipStatusSuspended: "suspended",
+	MembershipStatusRejected:  "rejected",
+}
+
+// ParseMembershipStatus
+// / Parses the membership status as found in the data feed list, e.g. "active" or "Not Joined". Case and
+// / separators are ignored. Unknown values return MembershipStatusUnknown and an error.
+func ParseMembershipStatus(s string) (MembershipStatus, error) {
+	normalized := strings.ToLower(strings.TrimSpace(s))
+	normalized = strings.NewReplacer("_", " ", "-", " ").Replace(normalized)
+
+	for status, name := range membershipStatusNames {
+		if name == normalized && status != MembershipStatusUnknown {
+			return status, nil
+		}
+	}
+	return MembershipStatusUnknown, fmt.Errorf("unknown membership status '%s'", s)
+}
+
+func (s MembershipStatus) String() string {
+	if name, ok := membershipStatusNames[s]; ok {
+		return name
+	}
+	return membershipStatusNames[MembershipStatusUnknown]
+}
+
+func (s MembershipStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *MembershipStatus) UnmarshalText(text []byte) error {
+	status, err := ParseMembershipStatus(string(text))
+	if err != nil && string(text) != membershipStatusNames[MembershipStatusUnknown] {
+		return err
+	}
+	*s = status
+	return nil
+}
+
+// DataFeed
+// / Typed companion of DataFeedListRow with parsed membership status, timestamps and product count.
+// / Row holds the raw row for all columns that are plain text. Empty columns leave the zero value.
+type DataFeed struct {
+	Row DataFeedListRow
+
+	MembershipStatus MembershipStatus
+	LastImported     time.Time
+	LastChecked      time.Time
+	NoOfProducts     int
+}
+
+// IsJoined
+// / Returns true if the publisher is an active member of the advertiser program, only then feeds can be downloaded.
+func (f DataFeed) IsJoined() bool {
+	return f.MembershipStatus == MembershipStatusActive
+}
+
+// ToDataFeed
+// / Converts the row into a DataFeed. Columns that cannot be parsed keep their zero value and are reported as
+// / FieldErrors, the returned DataFeed is never nil so callers can decide to use the partial result.
+func (r DataFeedListRow) ToDataFeed() (*DataFeed, error) {
+	f := &DataFeed{Row: r}
+	parser := fieldParser{}
+
+	if strings.TrimSpace(r.MembershipStatus) != "" {
+		status, err := ParseMembershipStatus(r.MembershipStatus)
+		if err != nil {
+			parser.fail(string(listColumnMembershipStatus), r.MembershipStatus, err)
+		}
+		f.MembershipStatus = status
+	}
+
+	f.LastImported = parser.date(string(listColumnLastImported), r.LastImported)
+	f.LastChecked = parser.date(string(listColumnLastChecked), r.LastChecked)
+	f.NoOfProducts = parser.int(string(listColumnNoOfProducts), strings.Replace(r.NoOfProducts, ",", "", -1))
+
+	if len(parser.errs) > 0 {
+		return f, parser.errs
+	}
+	return f, nil
+}
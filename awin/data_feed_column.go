@@ -0,0 +1,139 @@
+package awin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DataFeedColumn
+// / Name of a column of the Awin product data feed, use the Column constants to select columns in DataFeedOptions.
+type DataFeedColumn string
+
+// Columns of the Awin product data feed, each one is mapped to the DataFeedEntry field of the same name
+const (
+	ColumnAwDeepLink                    DataFeedColumn = "aw_deep_link"
+	ColumnProductName                   DataFeedColumn = "product_name"
+	ColumnAwProductId                   DataFeedColumn = "aw_product_id"
+	ColumnMerchantProductId             DataFeedColumn = "merchant_product_id"
+	ColumnMerchantImageUrl              DataFeedColumn = "merchant_image_url"
+	ColumnDescription                   DataFeedColumn = "description"
+	ColumnMerchantCategory              DataFeedColumn = "merchant_category"
+	ColumnSearchPrice                   DataFeedColumn = "search_price"
+	ColumnMerchantName                  DataFeedColumn = "merchant_name"
+	ColumnMerchantId                    DataFeedColumn = "merchant_id"
+	ColumnCategoryName                  DataFeedColumn = "category_name"
+	ColumnCategoryId                    DataFeedColumn = "category_id"
+	ColumnAwImageUrl                    DataFeedColumn = "aw_image_url"
+	ColumnCurrency                      DataFeedColumn = "currency"
+	ColumnStorePrice                    DataFeedColumn = "store_price"
+	ColumnDeliveryCost                  DataFeedColumn = "delivery_cost"
+	ColumnMerchantDeepLink              DataFeedColumn = "merchant_deep_link"
+	ColumnLanguage                      DataFeedColumn = "language"
+	ColumnLastUpdated                   DataFeedColumn = "last_updated"
+	ColumnDisplayPrice                  DataFeedColumn = "display_price"
+	ColumnDataFeedId                    DataFeedColumn = "data_feed_id"
+	ColumnBrandName                     DataFeedColumn = "brand_name"
+	ColumnBrandId                       DataFeedColumn = "brand_id"
+	ColumnColour                        DataFeedColumn = "colour"
+	ColumnProductShortDescription       DataFeedColumn = "product_short_description"
+	ColumnSpecifications                DataFeedColumn = "specifications"
+	ColumnCondition                     DataFeedColumn = "condition"
+	ColumnProductModel                  DataFeedColumn = "product_model"
+	ColumnModelNumber                   DataFeedColumn = "model_number"
+	ColumnDimensions                    DataFeedColumn = "dimensions"
+	ColumnKeywords                      DataFeedColumn = "keywords"
+	ColumnPromotionalText               DataFeedColumn = "promotional_text"
+	ColumnProductType                   DataFeedColumn = "product_type"
+	ColumnCommissionGroup               DataFeedColumn = "commission_group"
+	ColumnMerchantProductCategoryPath   DataFeedColumn = "merchant_product_category_path"
+	ColumnMerchantProductSecondCategory DataFeedColumn = "merchant_product_second_category"
+	ColumnMerchantProductThirdCategory  DataFeedColumn = "merchant_product_third_category"
+	ColumnRrpPrice                      DataFeedColumn = "rrp_price"
+	ColumnSaving                        DataFeedColumn = "saving"
+	ColumnSavingsPercent                DataFeedColumn = "savings_percent"
+	ColumnBasePrice                     DataFeedColumn = "base_price"
+	ColumnBasePriceAmount               DataFeedColumn = "base_price_amount"
+	ColumnBasePriceText                 DataFeedColumn = "base_price_text"
+	ColumnProductPriceOld               DataFeedColumn = "product_price_old"
+	ColumnDeliveryRestrictions          DataFeedColumn = "delivery_restrictions"
+	ColumnDeliveryWeight                DataFeedColumn = "delivery_weight"
+	ColumnWarranty                      DataFeedColumn = "warranty"
+	ColumnTermsOfContract               DataFeedColumn = "terms_of_contract"
+	ColumnDeliveryTime                  DataFeedColumn = "delivery_time"
+	ColumnInStock                       DataFeedColumn = "in_stock"
+	ColumnStockQuantity                 DataFeedColumn = "stock_quantity"
+	ColumnValidFrom                     DataFeedColumn = "valid_from"
+	ColumnValidTo                       DataFeedColumn = "valid_to"
+	ColumnIsForSale                     DataFeedColumn = "is_for_sale"
+	ColumnWebOffer                      DataFeedColumn = "web_offer"
+	ColumnPreOrder                      DataFeedColumn = "pre_order"
+	ColumnStockStatus                   DataFeedColumn = "stock_status"
+	ColumnSizeStockStatus               DataFeedColumn = "size_stock_status"
+	ColumnSizeStockAmount               DataFeedColumn = "size_stock_amount"
+	ColumnMerchantThumbUrl              DataFeedColumn = "merchant_thumb_url"
+	ColumnLargeImage                    DataFeedColumn = "large_image"
+	ColumnAlternateImage                DataFeedColumn = "alternate_image"
+	ColumnAwThumbUrl                    DataFeedColumn = "aw_thumb_url"
+	ColumnAlternateImageTwo             DataFeedColumn = "alternate_image_two"
+	ColumnAlternateImageThree           DataFeedColumn = "alternate_image_three"
+	ColumnAlternateImageFour            DataFeedColumn = "alternate_image_four"
+	ColumnReviews                       DataFeedColumn = "reviews"
+	ColumnAverageRating                 DataFeedColumn = "average_rating"
+	ColumnRating                        DataFeedColumn = "rating"
+	ColumnNumberAvailable               DataFeedColumn = "number_available"
+	ColumnCustom1                       DataFeedColumn = "custom_1"
+	ColumnCustom2                       DataFeedColumn = "custom_2"
+	ColumnCustom3                       DataFeedColumn = "custom_3"
+	ColumnCustom4                       DataFeedColumn = "custom_4"
+	ColumnCustom5                       DataFeedColumn = "custom_5"
+	ColumnCustom6                       DataFeedColumn = "custom_6"
+	ColumnCustom7                       DataFeedColumn = "custom_7"
+	ColumnCustom8                       DataFeedColumn = "custom_8"
+	ColumnCustom9                       DataFeedColumn = "custom_9"
+	ColumnEan                           DataFeedColumn = "ean"
+	ColumnIsbn                          DataFeedColumn = "isbn"
+	ColumnUpc                           DataFeedColumn = "upc"
+	ColumnMpn                           DataFeedColumn = "mpn"
+	ColumnParentProductId               DataFeedColumn = "parent_product_id"
+	ColumnProductGtin                   DataFeedColumn = "product_GTIN"
+	ColumnBasketLink                    DataFeedColumn = "basket_link"
+)
+
+// DataFeedColumns
+// / Returns all known data feed columns in the order Awin delivers them.
+func DataFeedColumns() []DataFeedColumn {
+	columns := make([]DataFeedColumn, len(dataFeedColumns))
+	copy(columns, dataFeedColumns)
+	return columns
+}
+
+// ValidateDataFeedColumns
+// / Returns an error if columns contains an unknown or duplicated column. Custom columns of a vertical, named like
+// / Fashion:size, are no unknown columns.
+func ValidateDataFeedColumns(columns []DataFeedColumn) error {
+	seen := make(map[DataFeedColumn]bool, len(columns))
+	for _, column := range columns {
+		if _, ok := dataFeedEntryFields[string(column)]; !ok && !customDataFeedColumn(column) {
+			return fmt.Errorf("unknown data feed column '%s'", column)
+		}
+		if seen[column] {
+			return fmt.Errorf("duplicated data feed column '%s'", column)
+		}
+		seen[column] = true
+	}
+	return nil
+}
+
+// customDataFeedColumn reports whether column is named like the custom columns of a vertical, e.g. Fashion:size
+func customDataFeedColumn(column DataFeedColumn) bool {
+	index := strings.Index(string(column), ":")
+	return index > 0 && index < len(column)-1
+}
+
+func joinDataFeedColumns(columns []DataFeedColumn) string {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = string(column)
+	}
+	return strings.Join(names, ",")
+}
@@ -1,5 +1,7 @@
 package awin
 
+import "reflect"
+
 type DataFeedEntry struct {
 	AwDeepLink                    string `json:"aw_deep_link,omitempty" csv:"aw_deep_link"`
 	ProductName                   string `json:"product_name,omitempty" csv:"product_name"`
@@ -87,4 +89,17 @@ type DataFeedEntry struct {
 	ParentProductId               string `json:"parent_product_id,omitempty" csv:"parent_product_id"`
 	ProductGtin                   string `json:"product_gtin,omitempty" csv:"product_GTIN"`
 	BasketLink                    string `json:"basket_link,omitempty" csv:"basket_link"`
+	// Extra holds the non empty values of feed columns without a field by column name, e.g. Fashion:size.
+	// If columns were requested, only the requested ones are kept.
+	Extra map[string]string `json:"extra,omitempty" csv:"-"`
+}
+
+// Value
+// / Returns the value of column, columns without a field are looked up in Extra.
+func (e *DataFeedEntry) Value(column DataFeedColumn) string {
+	index, ok := dataFeedEntryFields[string(column)]
+	if !ok {
+		return e.Extra[string(column)]
+	}
+	return reflect.ValueOf(e).Elem().Field(index).String()
 }
@@ -0,0 +1,308 @@
+package awin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DataFeedFormat
+// / Output format of a data feed download.
+type DataFeedFormat string
+
+const (
+	FormatCsv     DataFeedFormat = "csv"
+	FormatXml     DataFeedFormat = "xml"
+	FormatXmlTree DataFeedFormat = "xmltree"
+	FormatJson    DataFeedFormat = "json"
+)
+
+var errNoJsonProducts = errors.New("json data feed contains no product array")
+
+// xmlProductElements are the element names of a single product in xml feeds
+var xmlProductElements = map[string]bool{"product": true, "prod": true}
+
+// xmlTreeAliases maps the element and attribute names of Awin's tree xml to columns, other elements are matched
+// by their column name
+var xmlTreeAliases = map[string]DataFeedColumn{
+	"prod@id":       ColumnAwProductId,
+	"product@id":    ColumnAwProductId,
+	"merchant@id":   ColumnMerchantId,
+	"merchant@name": ColumnMerchantName,
+	"price@curr":    ColumnCurrency,
+	"pid":           ColumnMerchantProductId,
+	"name":          ColumnProductName,
+	"desc":          ColumnDescription,
+	"awtrack":       ColumnAwDeepLink,
+	"awimage":       ColumnAwImageUrl,
+	"awthumb":       ColumnAwThumbUrl,
+	"mimage":        ColumnMerchantImageUrl,
+	"mthumb":        ColumnMerchantThumbUrl,
+	"mlink":         ColumnMerchantDeepLink,
+	"buynow":        ColumnSearchPrice,
+	"store":         ColumnStorePrice,
+	"rrp":           ColumnRrpPrice,
+	"delivery":      ColumnDeliveryCost,
+	"awcat":         ColumnCategoryName,
+	"awcatid":       ColumnCategoryId,
+	"mcat":          ColumnMerchantCategory,
+	"brand":         ColumnBrandName,
+	"brandid":       ColumnBrandId,
+	"spec":          ColumnSpecifications,
+	"promo":         ColumnPromotionalText,
+}
+
+func (f DataFeedFormat) validate() error {
+	switch f {
+	case FormatCsv, FormatXml, FormatXmlTree, FormatJson:
+		return nil
+	}
+	return fmt.Errorf("unknown data feed format '%s'", f)
+}
+
+// detectDataFeedFormat peeks at the first characters of r, xml starts with '<', json with '[' or '{'
+func detectDataFeedFormat(r *bufio.Reader) DataFeedFormat {
+	head, _ := r.Peek(512)
+	head = bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))
+	head = bytes.TrimLeft(head, " \t\r\n")
+
+	if len(head) == 0 {
+		return FormatCsv
+	}
+	switch head[0] {
+	case '<':
+		return FormatXml
+	case '[', '{':
+		return FormatJson
+	default:
+		return FormatCsv
+	}
+}
+
+// xmlDecoder decodes flat and tree xml feeds. Every product or prod element is one entry, its leaf elements and
+// attributes are matched against the columns, nesting is ignored.
+type xmlDecoder struct {
+	decoder  *xml.Decoder
+	fields   fieldSetter
+	merchant []xml.Attr
+}
+
+func newXmlDecoder(r io.Reader, fields fieldSetter) *xmlDecoder {
+	return &xmlDecoder{decoder: xml.NewDecoder(r), fields: fields}
+}
+
+func (d *xmlDecoder) decode(entry *DataFeedEntry) error {
+	for {
+		token, err := d.decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		name := strings.ToLower(start.Name.Local)
+		if name == "merchant" {
+			// Tree feeds group products by merchant, its attributes apply to all of them
+			d.merchant = start.Attr
+		}
+		if xmlProductElements[name] {
+			d.setAttributes(entry, "merchant", d.merchant)
+			d.setAttributes(entry, name, start.Attr)
+			return d.decodeProduct(entry)
+		}
+	}
+}
+
+// decodeProduct reads the children of a product element until its end element
+func (d *xmlDecoder) decodeProduct(entry *DataFeedEntry) error {
+	type element struct {
+		name     string
+		raw      string
+		text     strings.Builder
+		children bool
+	}
+	var stack []*element
+
+	for {
+		token, err := d.decoder.Token()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if len(stack) > 0 {
+				stack[len(stack)-1].children = true
+			}
+			raw := t.Name.Local
+			if t.Name.Space != "" {
+				// undeclared prefixes like in Fashion:size are kept as the space
+				raw = t.Name.Space + ":" + raw
+			}
+			name := strings.ToLower(raw)
+			d.setAttributes(entry, name, t.Attr)
+			stack = append(stack, &element{name: name, raw: raw})
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		case xml.EndElement:
+			if len(stack) == 0 {
+				// End of the product element
+				return nil
+			}
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if !current.children {
+				d.set(entry, current.name, current.raw, strings.TrimSpace(current.text.String()))
+			}
+		}
+	}
+}
+
+func (d *xmlDecoder) setAttributes(entry *DataFeedEntry, element string, attributes []xml.Attr) {
+	for _, attr := range attributes {
+		if column, ok := xmlTreeAliases[element+"@"+strings.ToLower(attr.Name.Local)]; ok {
+			d.set(entry, string(column), string(column), attr.Value)
+		}
+	}
+}
+
+// set stores value in the field matching name, selected elements without a field or alias are kept in Extra as raw
+func (d *xmlDecoder) set(entry *DataFeedEntry, name string, raw string, value string) {
+	index := d.fields.indexFold(name)
+	column, alias := xmlTreeAliases[name]
+	if index < 0 && alias {
+		index = d.fields.index(string(column))
+	}
+	if index >= 0 {
+		d.fields.set(entry, index, value)
+	} else if !alias && d.fields.extraColumnFold(name) {
+		d.fields.setExtra(entry, raw, value)
+	}
+}
+
+// jsonDecoder decodes json feeds, either an array of product objects or an object holding such an array as its
+// first array value.
+// Nested objects are flattened, their keys are matched against the columns.
+type jsonDecoder struct {
+	decoder *json.Decoder
+	fields  fieldSetter
+	done    bool
+}
+
+func newJsonDecoder(r io.Reader, fields fieldSetter) (*jsonDecoder, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	token, err := jsonToken(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if token == json.Delim('[') {
+		return &jsonDecoder{decoder: decoder, fields: fields}, nil
+	}
+	if token != json.Delim('{') {
+		return nil, errNoJsonProducts
+	}
+
+	// The products are the first array value of the top level object, other values like metadata are skipped
+	for decoder.More() {
+		if _, err := jsonToken(decoder); err != nil {
+			return nil, err
+		}
+		value, err := jsonToken(decoder)
+		if err != nil {
+			return nil, err
+		}
+		if value == json.Delim('[') {
+			return &jsonDecoder{decoder: decoder, fields: fields}, nil
+		}
+		if value == json.Delim('{') {
+			if err := skipJsonObject(decoder); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nil, errNoJsonProducts
+}
+
+// jsonToken returns the next token, errNoJsonProducts if the document ends
+func jsonToken(decoder *json.Decoder) (json.Token, error) {
+	token, err := decoder.Token()
+	if err == io.EOF {
+		return nil, errNoJsonProducts
+	}
+	return token, err
+}
+
+// skipJsonObject skips the rest of an object whose opening brace has been read
+func skipJsonObject(decoder *json.Decoder) error {
+	for depth := 1; depth > 0; {
+		token, err := jsonToken(decoder)
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+	return nil
+}
+
+func (d *jsonDecoder) decode(entry *DataFeedEntry) error {
+	if d.done || !d.decoder.More() {
+		d.done = true
+		return io.EOF
+	}
+
+	var values map[string]interface{}
+	if err := d.decoder.Decode(&values); err != nil {
+		return err
+	}
+	d.setValues(entry, values)
+	return nil
+}
+
+func (d *jsonDecoder) setValues(entry *DataFeedEntry, values map[string]interface{}) {
+	for key, value := range values {
+		var text string
+		switch v := value.(type) {
+		case map[string]interface{}:
+			d.setValues(entry, v)
+			continue
+		case string:
+			text = v
+		case json.Number:
+			text = v.String()
+		case bool:
+			text = strconv.FormatBool(v)
+		case nil:
+			text = ""
+		default:
+			encoded, _ := json.Marshal(v)
+			text = string(encoded)
+		}
+
+		if index := d.fields.indexFold(key); index >= 0 {
+			d.fields.set(entry, index, text)
+		} else if d.fields.extraColumnFold(key) {
+			d.fields.setExtra(entry, key, text)
+		}
+	}
+}
@@ -0,0 +1,369 @@
+package awin
+
+import (
+	"bufio"
+	"encoding/csv"
+	"github.com/gocarina/gocsv"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+var (
+	// dataFeedEntryFields maps csv column names to the index of the matching DataFeedEntry field
+	dataFeedEntryFields map[string]int
+
+	// dataFeedEntryFieldsFold maps lower case column and json names to field indexes, used by xml and json feeds
+	dataFeedEntryFieldsFold map[string]int
+)
+
+func init() {
+	entryType := reflect.TypeOf(DataFeedEntry{})
+	dataFeedEntryFields = make(map[string]int, entryType.NumField())
+	dataFeedEntryFieldsFold = make(map[string]int, entryType.NumField())
+	for i := 0; i < entryType.NumField(); i++ {
+		if column := entryType.Field(i).Tag.Get("csv"); column != "" && column != "-" {
+			dataFeedEntryFields[column] = i
+			dataFeedEntryFieldsFold[strings.ToLower(column)] = i
+		}
+		if name := strings.Split(entryType.Field(i).Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+			dataFeedEntryFieldsFold[strings.ToLower(name)] = i
+		}
+	}
+}
+
+// entryDecoder decodes the next entry of a feed into entry and returns io.EOF at the end of the feed
+type entryDecoder interface {
+	decode(entry *DataFeedEntry) error
+}
+
+// DataFeedReader
+// / Streams DataFeedEntry rows one at a time from a data feed, so memory stays flat regardless of feed size.
+// / Call Next until it returns false, then check Err. Close releases the underlying response body.
+type DataFeedReader struct {
+	decoder  entryDecoder
+	closer   io.Closer
+	counter  *countingReader
+	progress *progressTracker
+	header   http.Header
+	report   *DecodeReport
+	drift    SchemaDrift
+	entry    DataFeedEntry
+	err      error
+
+	rows     int
+	finished bool
+	// onFinish is set by the AwinClient to log and measure the outcome of the download
+	onFinish func(ended bool)
+}
+
+// NewDataFeedReader
+// / Returns a new DataFeedReader reading a plain (already decompressed) feed from r.
+// / The format is detected from the content: csv, xml (flat or tree) or json. The delimiter of csv feeds is detected
+// / from the header line.
+// / Columns are matched to DataFeedEntry fields by their csv tag, columns without a matching field are ignored.
+func NewDataFeedReader(r io.Reader) (*DataFeedReader, error) {
+	return newDataFeedReader(r, "", 0, nil, nil)
+}
+
+// NewDataFeedReaderWithFormat
+// / Same as NewDataFeedReader, but skips the detection and decodes r in the given format.
+func NewDataFeedReaderWithFormat(r io.Reader, format DataFeedFormat) (*DataFeedReader, error) {
+	if err := format.validate(); err != nil {
+		return nil, err
+	}
+	return newDataFeedReader(r, format, 0, nil, nil)
+}
+
+// NewLenientDataFeedReader
+// / Same as NewDataFeedReader, but csv rows with broken quoting or a wrong number of fields are skipped and recorded
+// / in Report instead of stopping the reader. Once the feed is read, Err returns a *RowErrorRatioError if the share of
+// / skipped rows is above maxErrorRatio, between 0 and 1. xml and json feeds are always decoded strictly.
+func NewLenientDataFeedReader(r io.Reader, maxErrorRatio float64) (*DataFeedReader, error) {
+	return newDataFeedReader(r, "", 0, nil, &lenientDecoding{maxErrorRatio: maxErrorRatio})
+}
+
+// newDataFeedReader decodes r in format and csv feeds with delimiter, both detected if empty, and only populates
+// the fields of the given columns, all fields if columns is empty. csv feeds skip invalid rows if lenient is set.
+func newDataFeedReader(r io.Reader, format DataFeedFormat, delimiter DataFeedDelimiter, columns []DataFeedColumn, lenient *lenientDecoding) (*DataFeedReader, error) {
+	fields := newFieldSetter(columns)
+
+	if format == "" || delimiter == 0 {
+		buffered := bufio.NewReader(r)
+		if format == "" {
+			format = detectDataFeedFormat(buffered)
+		}
+		if format == FormatCsv && delimiter == 0 {
+			delimiter = detectDelimiter(buffered)
+		}
+		r = buffered
+	}
+
+	var decoder entryDecoder
+	var report *DecodeReport
+	var drift SchemaDrift
+	var err error
+	switch format {
+	case FormatXml, FormatXmlTree:
+		decoder = newXmlDecoder(r, fields)
+	case FormatJson:
+		decoder, err = newJsonDecoder(r, fields)
+	default:
+		var csvFeed *csvDecoder
+		csvFeed, err = newCsvDecoder(r, delimiter, fields)
+		if err == nil {
+			drift = detectSchemaDrift(csvFeed.header, columns)
+			if lenient != nil {
+				csvFeed.lenient, csvFeed.report = lenient, &DecodeReport{}
+				report = csvFeed.report
+			}
+		}
+		decoder = csvFeed
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &DataFeedReader{decoder: decoder, report: report, drift: drift}, nil
+}
+
+// Next
+// / Decodes the next row. Returns false when the feed is exhausted or an error occurred.
+func (r *DataFeedReader) Next() bool {
+	if r.err != nil {
+		return false
+	}
+
+	r.entry = DataFeedEntry{}
+	if err := r.decoder.decode(&r.entry); err != nil {
+		if err != io.EOF {
+			r.err = err
+		}
+		r.finish(true)
+		return false
+	}
+
+	r.rows++
+	if r.progress != nil {
+		r.progress.row()
+	}
+	return true
+}
+
+// Entry
+// / Returns the entry decoded by the last successful call to Next.
+func (r *DataFeedReader) Entry() DataFeedEntry {
+	return r.entry
+}
+
+// Err
+// / Returns the first error that stopped Next, io.EOF is not reported.
+func (r *DataFeedReader) Err() error {
+	return r.err
+}
+
+// Report
+// / Returns the rows decoded so far, the schema drift of csv feeds and the rows skipped by a lenient reader, see
+// / NewLenientDataFeedReader and WithLenientDecoding.
+func (r *DataFeedReader) Report() DecodeReport {
+	var report DecodeReport
+	if r.report != nil {
+		report = *r.report
+	}
+	report.Rows = r.rows
+	report.Drift = r.drift
+	return report
+}
+
+// SchemaDrift
+// / Returns the difference between the csv header and the expected columns, empty for xml and json feeds.
+// / Readers created by NewDataFeedReader expect all columns of DataFeedColumns.
+func (r *DataFeedReader) SchemaDrift() SchemaDrift {
+	return r.drift
+}
+
+// BytesRead
+// / Returns the number of bytes downloaded so far, for compressed feeds these are the compressed bytes.
+// / Always 0 for readers created by NewDataFeedReader.
+func (r *DataFeedReader) BytesRead() int64 {
+	if r.counter == nil {
+		return 0
+	}
+	return r.counter.n
+}
+
+// Close
+// / Closes the underlying source, if the reader was created by the AwinClient this is the response body.
+func (r *DataFeedReader) Close() error {
+	r.finish(false)
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+// finish reports the end of the feed once, ended is false if the reader was closed before Next returned false
+func (r *DataFeedReader) finish(ended bool) {
+	if r.finished {
+		return
+	}
+	r.finished = true
+
+	if r.progress != nil {
+		r.progress.finish(r.err)
+	}
+	if r.onFinish != nil {
+		r.onFinish(ended)
+	}
+}
+
+// fieldSetter resolves column names to DataFeedEntry fields, restricted to the selected columns.
+// Selected columns without a field are kept in extra by their lower case name.
+type fieldSetter struct {
+	selected map[int]bool
+	extra    map[string]bool
+}
+
+func newFieldSetter(columns []DataFeedColumn) fieldSetter {
+	if len(columns) == 0 {
+		return fieldSetter{}
+	}
+
+	selected := make(map[int]bool, len(columns))
+	extra := map[string]bool{}
+	for _, column := range columns {
+		if index, ok := dataFeedEntryFields[string(column)]; ok {
+			selected[index] = true
+		} else {
+			extra[strings.ToLower(string(column))] = true
+		}
+	}
+	return fieldSetter{selected: selected, extra: extra}
+}
+
+// index returns the field index of the exactly matching column, -1 if the column is unknown or not selected
+func (f fieldSetter) index(column string) int {
+	index, ok := dataFeedEntryFields[column]
+	return f.filter(index, ok)
+}
+
+// indexFold is index ignoring case and also accepting the json names of the fields
+func (f fieldSetter) indexFold(column string) int {
+	index, ok := dataFeedEntryFieldsFold[strings.ToLower(column)]
+	return f.filter(index, ok)
+}
+
+func (f fieldSetter) filter(index int, ok bool) int {
+	if !ok || f.selected != nil && !f.selected[index] {
+		return -1
+	}
+	return index
+}
+
+func (f fieldSetter) set(entry *DataFeedEntry, index int, value string) {
+	reflect.ValueOf(entry).Elem().Field(index).SetString(value)
+}
+
+// extraColumn reports whether the values of column belong to Extra, it has no field and is selected or no columns
+// were selected
+func (f fieldSetter) extraColumn(column string) bool {
+	_, ok := dataFeedEntryFields[column]
+	return !ok && (f.selected == nil || f.extra[strings.ToLower(column)])
+}
+
+// extraColumnFold is extraColumn ignoring case and also accepting the json names of the fields
+func (f fieldSetter) extraColumnFold(column string) bool {
+	_, ok := dataFeedEntryFieldsFold[strings.ToLower(column)]
+	return !ok && (f.selected == nil || f.extra[strings.ToLower(column)])
+}
+
+// setExtra stores the value of an unknown column in entry.Extra, empty values are left out
+func (f fieldSetter) setExtra(entry *DataFeedEntry, column string, value string) {
+	if value == "" {
+		return
+	}
+	if entry.Extra == nil {
+		entry.Extra = map[string]string{}
+	}
+	entry.Extra[column] = value
+}
+
+// csvDecoder decodes csv feeds, the fields of each column are resolved once from the header.
+// extra holds the names of the columns collected in Extra and is empty for the other columns.
+type csvDecoder struct {
+	reader  *csv.Reader
+	fields  fieldSetter
+	header  []string
+	index   []int
+	extra   []string
+	lenient *lenientDecoding
+	report  *DecodeReport
+}
+
+func newCsvDecoder(r io.Reader, delimiter DataFeedDelimiter, fields fieldSetter) (*csvDecoder, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = rune(delimiter)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, gocsv.ErrEmptyCSVFile
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	header = append([]string{}, header...)
+	index := make([]int, len(header))
+	var extra []string
+	for i, column := range header {
+		column = strings.TrimSpace(column)
+		index[i] = fields.index(column)
+		if fields.extraColumn(column) {
+			if extra == nil {
+				extra = make([]string, len(header))
+			}
+			extra[i] = column
+		}
+	}
+
+	return &csvDecoder{reader: reader, fields: fields, header: header, index: index, extra: extra}, nil
+}
+
+func (d *csvDecoder) decode(entry *DataFeedEntry) error {
+	var record []string
+	var err error
+	if d.lenient != nil {
+		record, err = d.lenient.readRecord(d.reader, d.report)
+	} else {
+		record, err = d.reader.Read()
+	}
+	if err != nil {
+		return err
+	}
+
+	for i, value := range record {
+		if i >= len(d.index) {
+			break
+		}
+		if d.index[i] >= 0 {
+			d.fields.set(entry, d.index[i], value)
+		} else if d.extra != nil && d.extra[i] != "" {
+			d.fields.setExtra(entry, d.extra[i], value)
+		}
+	}
+	return nil
+}
+
+// countingReader counts the bytes read from r
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
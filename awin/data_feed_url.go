@@ -0,0 +1,279 @@
+package awin
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const dataFeedDownloadPath = "/datafeed/download"
+
+var errMissingApiKey = errors.New("data feed url has no api key")
+
+// DataFeedURL
+// / A productdata download url as generated by Create-a-Feed, split into its parts.
+// / BaseUrl Scheme and host, plus any path in front of /datafeed/download
+// / ApiKey The download api key
+// / Columns The requested columns, nil if all columns are requested
+// / Format, Delimiter Empty if the url does not specify them, rendered with their defaults
+// / Compression CompressionNone if the url has no compression, rendered as CompressionGzip if empty
+// / ExtraParams Path parameters the client does not know, e.g. category or brand filters, kept when rendering
+type DataFeedURL struct {
+	BaseUrl          string
+	ApiKey           string
+	Language         string
+	FeedIds          []string
+	Columns          []DataFeedColumn
+	Format           DataFeedFormat
+	Delimiter        DataFeedDelimiter
+	Compression      DataFeedCompression
+	ShowAdultContent bool
+	ExtraParams      map[string]string
+}
+
+// NewDataFeedURL
+// / Returns the download url of the given options on the default Awin host.
+func NewDataFeedURL(apiKey string, options DataFeedOptions) DataFeedURL {
+	return newDataFeedURL(defaultBaseUrl, apiKey, options)
+}
+
+func newDataFeedURL(baseUrl string, apiKey string, options DataFeedOptions) DataFeedURL {
+	return DataFeedURL{
+		BaseUrl:          baseUrl,
+		ApiKey:           apiKey,
+		Language:         options.Language,
+		FeedIds:          options.FeedIds,
+		Columns:          options.Columns,
+		Format:           options.Format,
+		Delimiter:        options.Delimiter,
+		Compression:      options.Compression,
+		ShowAdultContent: options.ShowAdultContent,
+	}
+}
+
+// ParseDataFeedURL
+// / Parses a productdata download url, like the ones copied from Create-a-Feed.
+// / Returns an error if it is no download url, has no api key or specifies an unknown format, delimiter or compression.
+// / Columns are not checked against the known columns, so urls requesting columns added by Awin later still parse.
+func ParseDataFeedURL(rawUrl string) (*DataFeedURL, error) {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, redactError(err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" || parsed.Host == "" {
+		return nil, fmt.Errorf("data feed url '%s' is no http url", redactUrl(rawUrl))
+	}
+
+	path := parsed.EscapedPath()
+	index := strings.Index(path, dataFeedDownloadPath+"/")
+	if index < 0 {
+		return nil, fmt.Errorf("data feed url '%s' is no download url", redactUrl(rawUrl))
+	}
+
+	u := &DataFeedURL{BaseUrl: parsed.Scheme + "://" + parsed.Host + path[:index]}
+
+	segments := strings.Split(strings.Trim(path[index+len(dataFeedDownloadPath):], "/"), "/")
+	if len(segments)%2 != 0 {
+		return nil, fmt.Errorf("data feed url '%s' has a parameter without value", redactUrl(rawUrl))
+	}
+
+	for i := 0; i < len(segments); i += 2 {
+		key := segments[i]
+		value, err := url.PathUnescape(segments[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("data feed url '%s' has an invalid %s: %w", redactUrl(rawUrl), key, err)
+		}
+		if err := u.setParam(key, value); err != nil {
+			return nil, fmt.Errorf("data feed url '%s': %w", redactUrl(rawUrl), err)
+		}
+	}
+
+	if u.Compression == "" {
+		// Awin does not compress feeds requested without compression
+		u.Compression = CompressionNone
+	}
+
+	if err := u.validate(); err != nil {
+		return nil, fmt.Errorf("data feed url '%s': %w", redactUrl(rawUrl), err)
+	}
+	return u, nil
+}
+
+func (u *DataFeedURL) setParam(key string, value string) error {
+	switch key {
+	case "apikey":
+		u.ApiKey = value
+	case "language":
+		u.Language = value
+	case "fid":
+		u.FeedIds = splitParam(value)
+	case "columns":
+		// The full column list is the default, keep it nil so options round-trip
+		if value != defaultDataFeedColumnsParam {
+			for _, column := range splitParam(value) {
+				u.Columns = append(u.Columns, DataFeedColumn(column))
+			}
+		}
+	case "format":
+		u.Format = DataFeedFormat(value)
+	case "delimiter":
+		delimiter, size := utf8.DecodeRuneInString(value)
+		if size != len(value) {
+			return fmt.Errorf("unsupported data feed delimiter '%s'", value)
+		}
+		u.Delimiter = DataFeedDelimiter(delimiter)
+	case "compression":
+		u.Compression = DataFeedCompression(value)
+	case "adultcontent":
+		switch value {
+		case "0":
+			u.ShowAdultContent = false
+		case "1":
+			u.ShowAdultContent = true
+		default:
+			return fmt.Errorf("invalid adultcontent value '%s'", value)
+		}
+	default:
+		if u.ExtraParams == nil {
+			u.ExtraParams = map[string]string{}
+		}
+		u.ExtraParams[key] = value
+	}
+	return nil
+}
+
+// validate checks the parts Awin rejects, empty format, delimiter and compression are allowed
+func (u DataFeedURL) validate() error {
+	if u.ApiKey == "" {
+		return errMissingApiKey
+	}
+	if u.Format != "" {
+		if err := u.Format.validate(); err != nil {
+			return err
+		}
+	}
+	if u.Delimiter != 0 {
+		if err := u.Delimiter.validate(); err != nil {
+			return err
+		}
+	}
+	if u.Compression != "" {
+		if err := u.Compression.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String
+// / Renders the download url. Empty language and feed ids are left out, the other parts fall back to their defaults:
+// / all columns, csv, comma and gzip.
+func (u DataFeedURL) String() string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSuffix(u.BaseUrl, "/"))
+	b.WriteString(dataFeedDownloadPath)
+
+	param := func(key string, value string) {
+		b.WriteString("/" + key + "/" + value)
+	}
+
+	param("apikey", escapeParam(u.ApiKey))
+	if u.Language != "" {
+		param("language", escapeParam(u.Language))
+	}
+	if len(u.FeedIds) > 0 {
+		param("fid", escapeList(u.FeedIds))
+	}
+
+	columns := defaultDataFeedColumnsParam
+	if len(u.Columns) > 0 {
+		names := make([]string, len(u.Columns))
+		for i, column := range u.Columns {
+			names[i] = string(column)
+		}
+		columns = escapeList(names)
+	}
+	param("columns", columns)
+
+	format := u.Format
+	if format == "" {
+		format = FormatCsv
+	}
+	param("format", string(format))
+
+	delimiter := u.Delimiter
+	if delimiter == 0 {
+		delimiter = DelimiterComma
+	}
+	param("delimiter", delimiter.urlParam())
+
+	compression := u.Compression
+	if compression == "" {
+		compression = CompressionGzip
+	}
+	if compression != CompressionNone {
+		param("compression", string(compression))
+	}
+
+	keys := make([]string, 0, len(u.ExtraParams))
+	for key := range u.ExtraParams {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		param(key, escapeParam(u.ExtraParams[key]))
+	}
+
+	adultContent := "0"
+	if u.ShowAdultContent {
+		adultContent = "1"
+	}
+	param("adultcontent", adultContent)
+
+	b.WriteString("/")
+	return b.String()
+}
+
+// Options
+// / Returns the DataFeedOptions requesting the same feed, NewDataFeedURL with them renders the url again.
+// / ExtraParams have no option and are lost.
+func (u DataFeedURL) Options() DataFeedOptions {
+	return DataFeedOptions{
+		FeedIds:          u.FeedIds,
+		Language:         u.Language,
+		ShowAdultContent: u.ShowAdultContent,
+		Columns:          u.Columns,
+		Format:           u.Format,
+		Delimiter:        u.Delimiter,
+		Compression:      u.Compression,
+	}
+}
+
+// request returns the download of rawUrl, the format and delimiter are detected from the response if empty
+func (u DataFeedURL) request(rawUrl string) dataFeedRequest {
+	return dataFeedRequest{url: rawUrl, feedIds: u.FeedIds, format: u.Format, delimiter: u.Delimiter, columns: u.Columns}
+}
+
+// escapeParam escapes value as path segment, commas separate lists and stay readable
+func escapeParam(value string) string {
+	return strings.ReplaceAll(url.PathEscape(value), "%2C", ",")
+}
+
+// escapeList escapes every value as part of a path segment and joins them with commas
+func escapeList(values []string) string {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = escapeParam(value)
+	}
+	return strings.Join(escaped, ",")
+}
+
+func splitParam(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
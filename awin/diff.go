@@ -0,0 +1,354 @@
+package awin
+
+import (
+	"fmt"
+	"sort"
+)
+
+// EntryIterator
+// / Source of a feed snapshot, implemented by DataFeedReader and by NewEntrySliceIterator for collected entries.
+type EntryIterator interface {
+	Next() bool
+	Entry() DataFeedEntry
+	Err() error
+}
+
+// DiffKey
+// / Returns the key that identifies a product across snapshots.
+type DiffKey func(entry *DataFeedEntry) string
+
+// DiffKeyAwProductId
+// / Identifies products by their Awin product id, the default key.
+func DiffKeyAwProductId(entry *DataFeedEntry) string {
+	return entry.AwProductId
+}
+
+// DiffKeyMerchantProduct
+// / Identifies products by merchant id and merchant product id, stable even if Awin assigns a new product id.
+func DiffKeyMerchantProduct(entry *DataFeedEntry) string {
+	return entry.MerchantId + "/" + entry.MerchantProductId
+}
+
+// DiffOptions
+// / Key Identifies products across snapshots, DiffKeyAwProductId if nil
+// / Columns The columns compared for modifications, all known columns if empty. Custom columns are compared by
+// / their Extra values.
+type DiffOptions struct {
+	Key     DiffKey
+	Columns []DataFeedColumn
+}
+
+// ChangeType
+// / Kind of change of a product between two snapshots.
+type ChangeType int
+
+const (
+	ChangeAdded ChangeType = iota
+	ChangeRemoved
+	ChangeModified
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	default:
+		return "modified"
+	}
+}
+
+// FieldChange
+// / A column whose value differs between the old and the new snapshot.
+type FieldChange struct {
+	Column DataFeedColumn
+	Old    string
+	New    string
+}
+
+// EntryChange
+// / A product that was added, removed or modified. Old is nil for added, New is nil for removed products.
+// / Fields lists the changed columns of modified products in column order.
+type EntryChange struct {
+	Type   ChangeType
+	Key    string
+	Old    *DataFeedEntry
+	New    *DataFeedEntry
+	Fields []FieldChange
+}
+
+// Changed
+// / Reports whether column is one of the changed Fields, e.g. to react on price or stock changes only.
+func (c EntryChange) Changed(column DataFeedColumn) bool {
+	for _, field := range c.Fields {
+		if field.Column == column {
+			return true
+		}
+	}
+	return false
+}
+
+// DataFeedDiff
+// / The changes between two snapshots grouped by type, each group is sorted by key.
+type DataFeedDiff struct {
+	Added    []EntryChange
+	Removed  []EntryChange
+	Modified []EntryChange
+}
+
+// DiffKeyError
+// / Returned if a snapshot contains a product with an empty or duplicated key, or a sorted snapshot is out of order.
+type DiffKeyError struct {
+	Key    string
+	Reason string
+}
+
+func (e *DiffKeyError) Error() string {
+	return fmt.Sprintf("invalid diff key '%s': %s", e.Key, e.Reason)
+}
+
+// DiffDataFeeds
+// / Compares two snapshots in any order and returns the grouped changes. The old snapshot is held in memory,
+// / the new one is streamed.
+func DiffDataFeeds(old EntryIterator, current EntryIterator, options DiffOptions) (*DataFeedDiff, error) {
+	diff := &DataFeedDiff{}
+	if err := StreamDiffDataFeeds(old, current, options, diff.add); err != nil {
+		return nil, err
+	}
+	diff.sort()
+	return diff, nil
+}
+
+// DiffDataFeedEntries
+// / Same as DiffDataFeeds for collected entries, e.g. the results of FetchDataFeed.
+func DiffDataFeedEntries(old []DataFeedEntry, current []DataFeedEntry, options DiffOptions) (*DataFeedDiff, error) {
+	return DiffDataFeeds(NewEntrySliceIterator(old), NewEntrySliceIterator(current), options)
+}
+
+// StreamDiffDataFeeds
+// / Compares two snapshots in any order by hashing the old one, emit is called with every change as soon as it is
+// / known: added and modified products while new is read, removed products at the end in key order.
+// / An error returned by emit stops the diff and is returned.
+func StreamDiffDataFeeds(old EntryIterator, current EntryIterator, options DiffOptions, emit func(change EntryChange) error) error {
+	differ := newEntryDiffer(options)
+
+	snapshot := map[string]*DataFeedEntry{}
+	for old.Next() {
+		entry := old.Entry()
+		key, err := differ.key(&entry)
+		if err != nil {
+			return err
+		}
+		if _, ok := snapshot[key]; ok {
+			return &DiffKeyError{Key: key, Reason: "duplicated in old snapshot"}
+		}
+		snapshot[key] = &entry
+	}
+	if err := old.Err(); err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+	for current.Next() {
+		entry := current.Entry()
+		key, err := differ.key(&entry)
+		if err != nil {
+			return err
+		}
+		if seen[key] {
+			return &DiffKeyError{Key: key, Reason: "duplicated in new snapshot"}
+		}
+		seen[key] = true
+
+		if change, ok := differ.compare(key, snapshot[key], &entry); ok {
+			if err := emit(change); err != nil {
+				return err
+			}
+		}
+		delete(snapshot, key)
+	}
+	if err := current.Err(); err != nil {
+		return err
+	}
+
+	removed := make([]string, 0, len(snapshot))
+	for key := range snapshot {
+		removed = append(removed, key)
+	}
+	sort.Strings(removed)
+	for _, key := range removed {
+		if err := emit(EntryChange{Type: ChangeRemoved, Key: key, Old: snapshot[key]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StreamDiffSortedDataFeeds
+// / Compares two snapshots sorted ascending by key with constant memory, e.g. exports sorted by the database.
+// / emit is called with every change in key order. Returns a DiffKeyError if a snapshot is not sorted.
+func StreamDiffSortedDataFeeds(old EntryIterator, current EntryIterator, options DiffOptions, emit func(change EntryChange) error) error {
+	differ := newEntryDiffer(options)
+	oldCursor := sortedCursor{iterator: old, differ: differ, name: "old"}
+	newCursor := sortedCursor{iterator: current, differ: differ, name: "new"}
+
+	if err := oldCursor.next(); err != nil {
+		return err
+	}
+	if err := newCursor.next(); err != nil {
+		return err
+	}
+
+	for oldCursor.entry != nil || newCursor.entry != nil {
+		var change EntryChange
+		var changed bool
+
+		switch {
+		case newCursor.entry == nil || oldCursor.entry != nil && oldCursor.key < newCursor.key:
+			change, changed = differ.compare(oldCursor.key, oldCursor.entry, nil)
+			if err := oldCursor.next(); err != nil {
+				return err
+			}
+		case oldCursor.entry == nil || newCursor.key < oldCursor.key:
+			change, changed = differ.compare(newCursor.key, nil, newCursor.entry)
+			if err := newCursor.next(); err != nil {
+				return err
+			}
+		default:
+			change, changed = differ.compare(newCursor.key, oldCursor.entry, newCursor.entry)
+			if err := oldCursor.next(); err != nil {
+				return err
+			}
+			if err := newCursor.next(); err != nil {
+				return err
+			}
+		}
+
+		if changed {
+			if err := emit(change); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// NewEntrySliceIterator
+// / Returns an EntryIterator over entries.
+func NewEntrySliceIterator(entries []DataFeedEntry) EntryIterator {
+	return &sliceIterator{entries: entries, index: -1}
+}
+
+type sliceIterator struct {
+	entries []DataFeedEntry
+	index   int
+}
+
+func (s *sliceIterator) Next() bool {
+	s.index++
+	return s.index < len(s.entries)
+}
+
+func (s *sliceIterator) Entry() DataFeedEntry {
+	return s.entries[s.index]
+}
+
+func (s *sliceIterator) Err() error {
+	return nil
+}
+
+// sortedCursor reads a sorted snapshot one entry ahead and checks the order of the keys
+type sortedCursor struct {
+	iterator EntryIterator
+	differ   entryDiffer
+	name     string
+	entry    *DataFeedEntry
+	key      string
+}
+
+func (c *sortedCursor) next() error {
+	if !c.iterator.Next() {
+		c.entry = nil
+		return c.iterator.Err()
+	}
+
+	entry := c.iterator.Entry()
+	key, err := c.differ.key(&entry)
+	if err != nil {
+		return err
+	}
+	if c.entry != nil && key <= c.key {
+		return &DiffKeyError{Key: key, Reason: fmt.Sprintf("%s snapshot is not sorted or has duplicates", c.name)}
+	}
+
+	c.entry, c.key = &entry, key
+	return nil
+}
+
+// entryDiffer compares entries by the configured key and columns
+type entryDiffer struct {
+	keyFunc DiffKey
+	columns []DataFeedColumn
+}
+
+func newEntryDiffer(options DiffOptions) entryDiffer {
+	differ := entryDiffer{keyFunc: options.Key, columns: options.Columns}
+	if differ.keyFunc == nil {
+		differ.keyFunc = DiffKeyAwProductId
+	}
+	if len(differ.columns) == 0 {
+		differ.columns = dataFeedColumns
+	}
+	return differ
+}
+
+func (d entryDiffer) key(entry *DataFeedEntry) (string, error) {
+	key := d.keyFunc(entry)
+	if key == "" {
+		return "", &DiffKeyError{Key: key, Reason: "empty key"}
+	}
+	return key, nil
+}
+
+// compare returns the change between old and current, false if both are equal in all compared columns
+func (d entryDiffer) compare(key string, old *DataFeedEntry, current *DataFeedEntry) (EntryChange, bool) {
+	switch {
+	case old == nil:
+		return EntryChange{Type: ChangeAdded, Key: key, New: current}, true
+	case current == nil:
+		return EntryChange{Type: ChangeRemoved, Key: key, Old: old}, true
+	}
+
+	var fields []FieldChange
+	for _, column := range d.columns {
+		oldField := old.Value(column)
+		newField := current.Value(column)
+		if oldField != newField {
+			fields = append(fields, FieldChange{Column: column, Old: oldField, New: newField})
+		}
+	}
+
+	if len(fields) == 0 {
+		return EntryChange{}, false
+	}
+	return EntryChange{Type: ChangeModified, Key: key, Old: old, New: current, Fields: fields}, true
+}
+
+func (d *DataFeedDiff) add(change EntryChange) error {
+	switch change.Type {
+	case ChangeAdded:
+		d.Added = append(d.Added, change)
+	case ChangeRemoved:
+		d.Removed = append(d.Removed, change)
+	default:
+		d.Modified = append(d.Modified, change)
+	}
+	return nil
+}
+
+func (d *DataFeedDiff) sort() {
+	for _, changes := range [][]EntryChange{d.Added, d.Removed, d.Modified} {
+		sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	}
+}
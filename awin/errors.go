@@ -0,0 +1,104 @@
+package awin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxErrorBodySize limits how much of an error response is kept in APIError.Body
+const maxErrorBodySize = 512
+
+// apiKeyPattern matches the api key path segment of all Awin productdata urls
+var apiKeyPattern = regexp.MustCompile(`(?i)(/apikey/)[^/?#]+`)
+
+// ErrorKind
+// / Classification of a failed Awin request.
+type ErrorKind int
+
+const (
+	ErrorKindUnknown ErrorKind = iota
+	ErrorKindAuth
+	ErrorKindNotFound
+	ErrorKindRateLimited
+	ErrorKindServer
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorKindAuth:
+		return "auth failure"
+	case ErrorKindNotFound:
+		return "not found"
+	case ErrorKindRateLimited:
+		return "rate limited"
+	case ErrorKindServer:
+		return "server error"
+	default:
+		return "unknown error"
+	}
+}
+
+// APIError
+// / Returned for every non 200 response of the Awin endpoints, use errors.As to inspect it.
+// / URL has the api key redacted and Body holds the first bytes of the response.
+// / RetryAfter is the delay requested by the Retry-After header, 0 if absent.
+type APIError struct {
+	StatusCode int
+	URL        string
+	Body       string
+	Kind       ErrorKind
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("awin request '%s' failed with status %d (%s): %s", e.URL, e.StatusCode, e.Kind, e.Body)
+}
+
+// newAPIError reads a snippet of the response body and classifies the status code
+func newAPIError(requestUrl string, resp *http.Response) *APIError {
+	snippet, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		URL:        redactUrl(requestUrl),
+		Body:       strings.TrimSpace(string(snippet)),
+		Kind:       errorKindFromStatus(resp.StatusCode),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+func errorKindFromStatus(statusCode int) ErrorKind {
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return ErrorKindAuth
+	case statusCode == http.StatusNotFound:
+		return ErrorKindNotFound
+	case statusCode == http.StatusTooManyRequests:
+		return ErrorKindRateLimited
+	case statusCode >= 500:
+		return ErrorKindServer
+	default:
+		return ErrorKindUnknown
+	}
+}
+
+// redactUrl replaces the api key of an Awin url, so it can be part of errors and logs
+func redactUrl(rawUrl string) string {
+	return apiKeyPattern.ReplaceAllString(rawUrl, "${1}REDACTED")
+}
+
+// redactError removes the api key from the url of transport errors returned by the http.Client
+func redactError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = redactUrl(urlErr.URL)
+	}
+	return err
+}
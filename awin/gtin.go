@@ -0,0 +1,198 @@
+package awin
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	errInvalidGtin    = errors.New("invalid GTIN, expected 8 to 14 digits")
+	errGtinCheckDigit = errors.New("invalid GTIN check digit")
+	errInvalidIsbn    = errors.New("invalid ISBN, expected an ISBN-10 or an ISBN-13 starting with 978 or 979")
+)
+
+// gtinReplacer removes the separators merchants format identifiers with
+var gtinReplacer = strings.NewReplacer(" ", "", "\u00a0", "", "-", "", ".", "")
+
+// identifierColumns are tried in order when picking the identifier of an entry, the isbn last as it only covers books
+var identifierColumns = []DataFeedColumn{ColumnProductGtin, ColumnEan, ColumnUpc, ColumnIsbn}
+
+// Gtin
+// / Global Trade Item Number in its canonical GTIN-14 form, GTIN-8, UPC (GTIN-12) and EAN (GTIN-13) numbers are
+// / padded with leading zeros. The zero value means no identifier.
+type Gtin string
+
+// ParseGtin
+// / Normalizes GTIN-8, UPC-A, EAN-13 and GTIN-14 numbers to a Gtin and validates the check digit.
+// / Spaces, hyphens and dots are ignored, as are leading zeros beyond 14 digits.
+func ParseGtin(s string) (Gtin, error) {
+	code := gtinReplacer.Replace(strings.TrimSpace(s))
+	for len(code) > 14 && code[0] == '0' {
+		code = code[1:]
+	}
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return "", errInvalidGtin
+	}
+	if !isDigits(code) || strings.Trim(code, "0") == "" {
+		return "", errInvalidGtin
+	}
+
+	code = strings.Repeat("0", 14-len(code)) + code
+	if gtinCheckDigit(code[:13]) != code[13] {
+		return "", errGtinCheckDigit
+	}
+	return Gtin(code), nil
+}
+
+// ParseIsbn
+// / Normalizes ISBN-10 and ISBN-13 numbers to a Gtin, ISBN-10 numbers are converted to their ISBN-13 with the 978
+// / prefix. Both check digits are validated, spaces and hyphens are ignored.
+func ParseIsbn(s string) (Gtin, error) {
+	code := strings.ToUpper(gtinReplacer.Replace(strings.TrimSpace(s)))
+	switch {
+	case len(code) == 13 && (strings.HasPrefix(code, "978") || strings.HasPrefix(code, "979")):
+		return ParseGtin(code)
+	case len(code) == 10 && isDigits(code[:9]) && (isDigits(code[9:]) || code[9] == 'X'):
+		if isbn10CheckDigit(code[:9]) != code[9] {
+			return "", errGtinCheckDigit
+		}
+		isbn13 := "978" + code[:9]
+		return Gtin("0" + isbn13 + string(gtinCheckDigit(isbn13))), nil
+	default:
+		return "", errInvalidIsbn
+	}
+}
+
+// String
+// / Returns the 14 digits of the GTIN.
+func (g Gtin) String() string {
+	return string(g)
+}
+
+// Gtin13
+// / Returns the EAN-13 form without the leading zero, GTIN-14 numbers of trade units are returned unchanged.
+func (g Gtin) Gtin13() string {
+	return strings.TrimPrefix(string(g), "0")
+}
+
+// Isbn13
+// / Returns the ISBN-13 if the GTIN is a book number with the 978 or 979 prefix, empty otherwise.
+func (g Gtin) Isbn13() string {
+	if code := g.Gtin13(); len(code) == 13 && (strings.HasPrefix(code, "978") || strings.HasPrefix(code, "979")) {
+		return code
+	}
+	return ""
+}
+
+// Global
+// / Reports whether the GTIN identifies a product across merchants. Numbers of the GS1 ranges for restricted
+// / circulation, like in-store and variable weight items, and coupons are only unique within a company.
+func (g Gtin) Global() bool {
+	if len(g) != 14 {
+		return false
+	}
+	if strings.HasPrefix(string(g), "000000") {
+		// GTIN-8 prefixes 0 and 2
+		return g[6] != '0' && g[6] != '2'
+	}
+
+	// GTIN-13 prefixes, UPC prefixes 2, 4 and 5 end up as 02, 04 and 05
+	prefix := string(g[1:4])
+	switch {
+	case prefix >= "020" && prefix <= "029", prefix >= "040" && prefix <= "059", prefix >= "200" && prefix <= "299":
+		return false
+	case prefix >= "981" && prefix <= "984", prefix >= "990":
+		return false
+	}
+	return true
+}
+
+// Identifiers
+// / Normalized global identifiers of an entry.
+// / Gtin The best identifier to match products across merchants, empty if the entry has no valid global one
+// / Column The column Gtin was taken from
+// / ProductGtin, Ean, Upc, Isbn The normalized columns, empty if the column is empty or invalid
+// / Mpn The trimmed manufacturer part number, only unique together with the brand
+type Identifiers struct {
+	Gtin        Gtin
+	Column      DataFeedColumn
+	ProductGtin Gtin
+	Ean         Gtin
+	Upc         Gtin
+	Isbn        Gtin
+	Mpn         string
+}
+
+// Identifiers
+// / Normalizes the product_GTIN, ean, upc and isbn columns and picks the first valid global one in this order as
+// / Gtin. Invalid values are left empty and reported as FieldErrors, the returned Identifiers are always usable.
+func (e DataFeedEntry) Identifiers() (Identifiers, error) {
+	var parser fieldParser
+	ids := Identifiers{
+		ProductGtin: parser.gtin(string(ColumnProductGtin), e.ProductGtin, ParseGtin),
+		Ean:         parser.gtin(string(ColumnEan), e.Ean, ParseGtin),
+		Upc:         parser.gtin(string(ColumnUpc), e.Upc, ParseGtin),
+		Isbn:        parser.gtin(string(ColumnIsbn), e.Isbn, ParseIsbn),
+		Mpn:         strings.TrimSpace(e.Mpn),
+	}
+
+	for i, gtin := range []Gtin{ids.ProductGtin, ids.Ean, ids.Upc, ids.Isbn} {
+		if gtin.Global() {
+			ids.Gtin, ids.Column = gtin, identifierColumns[i]
+			break
+		}
+	}
+
+	if len(parser.errs) > 0 {
+		return ids, parser.errs
+	}
+	return ids, nil
+}
+
+func (f *fieldParser) gtin(column string, value string, parse func(s string) (Gtin, error)) Gtin {
+	if strings.TrimSpace(value) == "" {
+		return ""
+	}
+	gtin, err := parse(value)
+	if err != nil {
+		f.fail(column, value, err)
+	}
+	return gtin
+}
+
+// gtinCheckDigit returns the GS1 check digit of the digits before it, weighted 3, 1, 3, ... from the right
+func gtinCheckDigit(digits string) byte {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		digit := int(digits[i] - '0')
+		if (len(digits)-i)%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// isbn10CheckDigit returns the modulo 11 check digit of the first 9 digits of an ISBN-10, X stands for 10
+func isbn10CheckDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += (10 - i) * int(digits[i]-'0')
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+	return byte('0' + check)
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
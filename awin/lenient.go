@@ -0,0 +1,90 @@
+package awin
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxReportedRowErrors limits the RowErrors kept in a DecodeReport, all skipped rows are counted
+const maxReportedRowErrors = 1000
+
+// RowError
+// / A csv row skipped by lenient decoding.
+// / Line The line the row starts at, the header is line 1
+// / Record The fields read from the row, nil if the row could not be split into fields
+// / Err The reason, usually a *csv.ParseError with csv.ErrFieldCount, csv.ErrQuote or csv.ErrBareQuote
+type RowError struct {
+	Line   int
+	Record []string
+	Err    error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("row at line %d: %v", e.Line, e.Err)
+}
+
+// DecodeReport
+// / Outcome of decoding a feed.
+// / Rows The decoded entries
+// / Skipped The rows skipped by lenient decoding
+// / Errors The first 1000 skipped rows with their reason
+// / Drift The difference between the csv header and the expected columns
+type DecodeReport struct {
+	Rows    int
+	Skipped int
+	Errors  []RowError
+	Drift   SchemaDrift
+}
+
+// ErrorRatio
+// / Returns the share of skipped rows among all rows between 0 and 1, 0 for empty feeds.
+func (r DecodeReport) ErrorRatio() float64 {
+	if r.Rows+r.Skipped == 0 {
+		return 0
+	}
+	return float64(r.Skipped) / float64(r.Rows+r.Skipped)
+}
+
+// RowErrorRatioError
+// / Returned at the end of a leniently decoded feed if more rows than MaxErrorRatio allows were skipped.
+type RowErrorRatioError struct {
+	Report        DecodeReport
+	MaxErrorRatio float64
+}
+
+func (e *RowErrorRatioError) Error() string {
+	return fmt.Sprintf("%d of %d rows of the feed are invalid, more than the allowed ratio of %g", e.Report.Skipped, e.Report.Rows+e.Report.Skipped, e.MaxErrorRatio)
+}
+
+// lenientDecoding configures csv decoders to skip invalid rows, nil decodes strictly
+type lenientDecoding struct {
+	maxErrorRatio float64
+}
+
+// readRecord reads the next valid record of reader, invalid ones are added to report and skipped
+func (l *lenientDecoding) readRecord(reader *csv.Reader, report *DecodeReport) ([]string, error) {
+	for {
+		record, err := reader.Read()
+		var parseErr *csv.ParseError
+		if err == nil || !errors.As(err, &parseErr) {
+			if err == io.EOF && report.ErrorRatio() > l.maxErrorRatio {
+				return nil, &RowErrorRatioError{Report: *report, MaxErrorRatio: l.maxErrorRatio}
+			}
+			if err == nil {
+				report.Rows++
+			}
+			return record, err
+		}
+
+		report.Skipped++
+		if len(report.Errors) < maxReportedRowErrors {
+			rowErr := RowError{Line: parseErr.StartLine, Err: err}
+			if record != nil {
+				rowErr.Record = append([]string{}, record...)
+			}
+			report.Errors = append(report.Errors, rowErr)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package awin
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Logger
+// / Structured logger used by the AwinClient, args are alternating keys and values.
+// / A *slog.Logger satisfies this interface and can be passed as is, NewStdLogger adapts a *log.Logger.
+// / The client logs requests and responses at debug, downloaded feeds and row counts at info, failed and retried
+// / requests at warn and failed downloads and decode errors at error level.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// LogLevel
+// / Minimum level of the messages written by NewStdLogger.
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+// String
+// / Returns the upper case name of the level, e.g. INFO.
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "DEBUG"
+	case LogLevelInfo:
+		return "INFO"
+	case LogLevelWarn:
+		return "WARN"
+	case LogLevelError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("LogLevel(%d)", int(l))
+	}
+}
+
+// NewStdLogger
+// / Returns a Logger writing messages of level and above to logger as "LEVEL msg key=value ...".
+func NewStdLogger(logger *log.Logger, level LogLevel) Logger {
+	return stdLogger{logger: logger, level: level}
+}
+
+// stdLogger writes to a *log.Logger, for code that cannot use log/slog yet
+type stdLogger struct {
+	logger *log.Logger
+	level  LogLevel
+}
+
+func (l stdLogger) Debug(msg string, args ...interface{}) { l.log(LogLevelDebug, msg, args) }
+func (l stdLogger) Info(msg string, args ...interface{})  { l.log(LogLevelInfo, msg, args) }
+func (l stdLogger) Warn(msg string, args ...interface{})  { l.log(LogLevelWarn, msg, args) }
+func (l stdLogger) Error(msg string, args ...interface{}) { l.log(LogLevelError, msg, args) }
+
+func (l stdLogger) log(level LogLevel, msg string, args []interface{}) {
+	if level < l.level {
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(level.String())
+	b.WriteString(" ")
+	b.WriteString(msg)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 < len(args) {
+			fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+		} else {
+			fmt.Fprintf(&b, " %v", args[i])
+		}
+	}
+	l.logger.Print(b.String())
+}
+
+// noopLogger discards all messages, it is used if no logger is configured
+type noopLogger struct{}
+
+func (noopLogger) Debug(string, ...interface{}) {}
+func (noopLogger) Info(string, ...interface{})  {}
+func (noopLogger) Warn(string, ...interface{})  {}
+func (noopLogger) Error(string, ...interface{}) {}
+
+// redactingLogger removes the api key from all strings and errors before they reach logger
+type redactingLogger struct {
+	logger Logger
+	apiKey string
+}
+
+// newRedactingLogger wraps logger, the noopLogger is returned as is
+func newRedactingLogger(logger Logger, apiKey string) Logger {
+	if _, ok := logger.(noopLogger); ok {
+		return logger
+	}
+	return redactingLogger{logger: logger, apiKey: apiKey}
+}
+
+func (l redactingLogger) Debug(msg string, args ...interface{}) {
+	l.logger.Debug(msg, l.redact(args)...)
+}
+
+func (l redactingLogger) Info(msg string, args ...interface{}) {
+	l.logger.Info(msg, l.redact(args)...)
+}
+
+func (l redactingLogger) Warn(msg string, args ...interface{}) {
+	l.logger.Warn(msg, l.redact(args)...)
+}
+
+func (l redactingLogger) Error(msg string, args ...interface{}) {
+	l.logger.Error(msg, l.redact(args)...)
+}
+
+// redact returns args with the api key replaced in all string values and in the messages of errors
+func (l redactingLogger) redact(args []interface{}) []interface{} {
+	redacted := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch value := arg.(type) {
+		case string:
+			redacted[i] = l.redactString(value)
+		case error:
+			// Errors are only replaced if needed, so loggers can still inspect them
+			if message := value.Error(); l.redactString(message) != message {
+				redacted[i] = l.redactString(message)
+			} else {
+				redacted[i] = value
+			}
+		default:
+			redacted[i] = arg
+		}
+	}
+	return redacted
+}
+
+func (l redactingLogger) redactString(value string) string {
+	value = redactUrl(value)
+	if l.apiKey != "" {
+		value = strings.ReplaceAll(value, l.apiKey, "REDACTED")
+	}
+	return value
+}
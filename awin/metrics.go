@@ -0,0 +1,86 @@
+package awin
+
+import (
+	"context"
+	"time"
+)
+
+// Endpoint
+// / The Awin endpoint a measurement belongs to.
+type Endpoint string
+
+const (
+	EndpointFeedList Endpoint = "feed_list"
+	EndpointDataFeed Endpoint = "data_feed"
+)
+
+// DownloadMetrics
+// / Outcome of a single download attempt, reported once its body has been read or closed.
+// / Endpoint EndpointFeedList or EndpointDataFeed
+// / FeedIds The feed ids of data feed downloads, empty for the feed list
+// / Rows Decoded feed list rows or feed entries
+// / Bytes Downloaded bytes, for compressed feeds these are the compressed bytes
+// / Duration Time from the request until the body was decoded, download and decoding run interleaved
+// / Err The error that stopped the decoding, nil if the body was read completely or closed early
+// / Complete True if the body was read completely without error, Rows then counts the whole feed
+type DownloadMetrics struct {
+	Endpoint Endpoint
+	FeedIds  []string
+	Rows     int
+	Bytes    int64
+	Duration time.Duration
+	Err      error
+	Complete bool
+}
+
+// Metrics
+// / Receives measurements of the AwinClient, see WithMetrics. Implementations must be safe for concurrent use.
+// / The awinprom and awinotel modules provide Prometheus and OpenTelemetry implementations.
+type Metrics interface {
+	// ObserveRequest is called for every request sent to Awin, statusCode is 0 if no response was received.
+	// Downloads served from a DiskCache are no requests.
+	ObserveRequest(endpoint Endpoint, statusCode int, duration time.Duration)
+	// ObserveRetry is called before every retry of a failed attempt
+	ObserveRetry(endpoint Endpoint)
+	// ObserveDownload is called for every attempt that got a response
+	ObserveDownload(download DownloadMetrics)
+}
+
+// Tracer
+// / Creates spans around FetchDataFeedList and every feed download, see WithTracer.
+// / The context returned by Start is used for the requests of the span.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span
+// / A span created by a Tracer. Attribute values are strings, ints, int64s or string slices.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	End(err error)
+}
+
+// Span names used by the AwinClient
+const (
+	SpanFetchDataFeedList = "awin.FetchDataFeedList"
+	SpanFetchDataFeed     = "awin.FetchDataFeed"
+)
+
+// noopMetrics discards all measurements, it is used if no metrics are configured
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveRequest(Endpoint, int, time.Duration) {}
+func (noopMetrics) ObserveRetry(Endpoint)                       {}
+func (noopMetrics) ObserveDownload(DownloadMetrics)             {}
+
+// noopTracer creates spans doing nothing, it is used if no tracer is configured
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, interface{}) {}
+func (noopSpan) End(error)                        {}
@@ -0,0 +1,122 @@
+package awin
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxDecimalScale limits the fractional digits of a Decimal, prices and percentages never need more
+const maxDecimalScale = 9
+
+var errInvalidDecimal = errors.New("invalid decimal number")
+
+// Decimal
+// / Exact decimal number without floating point rounding, the value is Unscaled * 10^-Scale.
+type Decimal struct {
+	Unscaled int64
+	Scale    int32
+}
+
+// ParseDecimal
+// / Parses numbers like "12", "-3.5" or "12.99". A single comma followed by one or two digits is accepted as
+// / decimal separator ("12,99") as merchants use both notations. Other commas, like in "1,234", are ambiguous
+// / thousands separators and return an error.
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	if comma := strings.IndexByte(s, ','); comma >= 0 {
+		if digits := len(s) - comma - 1; strings.Count(s, ",") > 1 || strings.Contains(s, ".") || digits < 1 || digits > 2 {
+			return Decimal{}, errInvalidDecimal
+		}
+		s = strings.Replace(s, ",", ".", 1)
+	}
+
+	negative := strings.HasPrefix(s, "-")
+	if negative || strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+
+	integer, fraction := s, ""
+	if dot := strings.IndexByte(s, '.'); dot >= 0 {
+		integer, fraction = s[:dot], s[dot+1:]
+	}
+	if integer == "" && fraction == "" || len(fraction) > maxDecimalScale {
+		return Decimal{}, errInvalidDecimal
+	}
+
+	var unscaled int64
+	for _, digit := range integer + fraction {
+		if digit < '0' || digit > '9' || unscaled > (math.MaxInt64-9)/10 {
+			return Decimal{}, errInvalidDecimal
+		}
+		unscaled = unscaled*10 + int64(digit-'0')
+	}
+	if negative {
+		unscaled = -unscaled
+	}
+
+	return Decimal{Unscaled: unscaled, Scale: int32(len(fraction))}, nil
+}
+
+// String
+// / Returns the decimal with all its fractional digits, e.g. "12.50".
+func (d Decimal) String() string {
+	digits := strconv.FormatInt(d.Unscaled, 10)
+	if d.Scale <= 0 {
+		return digits
+	}
+
+	sign := ""
+	if d.Unscaled < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	if pad := int(d.Scale) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	split := len(digits) - int(d.Scale)
+	return sign + digits[:split] + "." + digits[split:]
+}
+
+// Float64
+// / Returns the nearest float64, only use it where rounding does not matter.
+func (d Decimal) Float64() float64 {
+	return float64(d.Unscaled) / math.Pow10(int(d.Scale))
+}
+
+// IsZero
+// / Returns true if the decimal is zero, regardless of its scale.
+func (d Decimal) IsZero() bool {
+	return d.Unscaled == 0
+}
+
+// Cmp
+// / Compares d and o and returns -1, 0 or +1. Equal values with different scales, like 1.5 and 1.50, compare equal.
+func (d Decimal) Cmp(o Decimal) int {
+	// aligning the scales can overflow int64, e.g. for 12345678901 and 0.000000001
+	a, b := big.NewInt(d.Unscaled), big.NewInt(o.Unscaled)
+	if d.Scale < o.Scale {
+		a.Mul(a, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(o.Scale-d.Scale)), nil))
+	} else if o.Scale < d.Scale {
+		b.Mul(b, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.Scale-o.Scale)), nil))
+	}
+	return a.Cmp(b)
+}
+
+// Money
+// / Decimal amount paired with its ISO 4217 currency code.
+type Money struct {
+	Amount   Decimal
+	Currency string
+}
+
+// String
+// / Returns the amount followed by the currency, e.g. "12.99 EUR".
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.Amount.String()
+	}
+	return fmt.Sprintf("%s %s", m.Amount, m.Currency)
+}
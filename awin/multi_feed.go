@@ -0,0 +1,93 @@
+package awin
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultFeedWorkers is used by FetchDataFeeds if no worker count is given
+const defaultFeedWorkers = 4
+
+// DataFeedResult
+// / Outcome of downloading a single feed with FetchDataFeeds.
+// / FeedId The id of the feed
+// / Entries The decoded entries, nil if Err is set
+// / Err The error of this feed, other feeds are not affected by it
+// / Duration Time spent on this feed including retries
+// / Bytes Downloaded (compressed) bytes of all attempts
+// / Report Rows skipped by lenient decoding in the last attempt, see WithLenientDecoding
+type DataFeedResult struct {
+	FeedId   string
+	Entries  *[]DataFeedEntry
+	Err      error
+	Duration time.Duration
+	Bytes    int64
+	Report   DecodeReport
+}
+
+// FetchDataFeeds
+// / Downloads every feed of options.FeedIds with a separate request, using up to workers feeds in parallel.
+// / A failing feed does not affect the others. Results are returned in the order of options.FeedIds.
+func (c AwinClient) FetchDataFeeds(options *DataFeedOptions, workers int) []DataFeedResult {
+	return c.FetchDataFeedsWithContext(context.Background(), options, workers)
+}
+
+// FetchDataFeedsWithContext
+// / Same as FetchDataFeeds, feeds not finished when ctx is cancelled report ctx.Err().
+func (c AwinClient) FetchDataFeedsWithContext(ctx context.Context, options *DataFeedOptions, workers int) []DataFeedResult {
+	options = c.dataFeedOptions(options)
+
+	results := make([]DataFeedResult, len(options.FeedIds))
+	runFeedWorkers(len(results), workers, func(i int) {
+		results[i] = c.fetchSingleDataFeed(ctx, options, options.FeedIds[i])
+	})
+
+	return results
+}
+
+// runFeedWorkers calls work for the indexes 0 to n-1 using up to workers goroutines and waits for all of them
+func runFeedWorkers(n int, workers int, work func(i int)) {
+	if workers < 1 {
+		workers = defaultFeedWorkers
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				work(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// fetchSingleDataFeed downloads the feed feedId with all other settings taken from options
+func (c AwinClient) fetchSingleDataFeed(ctx context.Context, options *DataFeedOptions, feedId string) DataFeedResult {
+	start := time.Now()
+	result := DataFeedResult{FeedId: feedId}
+
+	feedOptions := *options
+	feedOptions.FeedIds = []string{feedId}
+
+	request, err := c.dataFeedRequest(&feedOptions)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	download, err := c.fetchDataFeedDownload(ctx, request)
+	result.Entries, result.Bytes, result.Report, result.Err = download.entries, download.bytes, download.report, err
+	result.Duration = time.Since(start)
+
+	return result
+}
@@ -0,0 +1,167 @@
+package awin
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option
+// / Configures an AwinClient created by New.
+type Option func(*AwinClient)
+
+// New
+// / Returns a new AwinClient for apiKey configured by opts.
+// / Without options the client talks to https://productdata.awin.com using its own http.Client.
+func New(apiKey string, opts ...Option) *AwinClient {
+	c := &AwinClient{
+		client:  &http.Client{},
+		apiKey:  apiKey,
+		baseUrl: defaultBaseUrl,
+		logger:  noopLogger{},
+		metrics: noopMetrics{},
+		tracer:  noopTracer{},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.logger = newRedactingLogger(c.logger, apiKey)
+
+	if c.timeout > 0 {
+		client := *c.client
+		client.Timeout = c.timeout
+		c.client = &client
+	}
+
+	return c
+}
+
+// WithBaseUrl
+// / Sends all requests to baseUrl instead of https://productdata.awin.com, e.g. a proxy or a local test server.
+func WithBaseUrl(baseUrl string) Option {
+	return func(c *AwinClient) {
+		c.baseUrl = strings.TrimSuffix(baseUrl, "/")
+	}
+}
+
+// WithHttpClient
+// / Uses client for all requests.
+func WithHttpClient(client *http.Client) Option {
+	return func(c *AwinClient) {
+		c.client = client
+	}
+}
+
+// WithUserAgent
+// / Sends userAgent as User-Agent header with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *AwinClient) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithTimeout
+// / Limits each request including the download of its body to timeout.
+// / The http.Client is copied, so a client passed by WithHttpClient is not modified.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *AwinClient) {
+		c.timeout = timeout
+	}
+}
+
+// WithLogger
+// / Logs requests, retries, downloaded feeds and errors to logger, see Logger for the levels.
+// / The api key is removed from all logged strings and errors, nil disables logging.
+func WithLogger(logger Logger) Option {
+	return func(c *AwinClient) {
+		if logger == nil {
+			logger = noopLogger{}
+		}
+		c.logger = logger
+	}
+}
+
+// WithMetrics
+// / Reports requests, retries and downloads to metrics, nil disables metrics.
+func WithMetrics(metrics Metrics) Option {
+	return func(c *AwinClient) {
+		if metrics == nil {
+			metrics = noopMetrics{}
+		}
+		c.metrics = metrics
+	}
+}
+
+// WithTracer
+// / Creates spans around FetchDataFeedList and every feed download with tracer, nil disables tracing.
+func WithTracer(tracer Tracer) Option {
+	return func(c *AwinClient) {
+		if tracer == nil {
+			tracer = noopTracer{}
+		}
+		c.tracer = tracer
+	}
+}
+
+// WithRetryPolicy
+// / Same as AwinClient.SetRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *AwinClient) {
+		c.SetRetryPolicy(policy)
+	}
+}
+
+// WithLimiter
+// / Same as AwinClient.SetLimiter.
+func WithLimiter(limiter Limiter) Option {
+	return func(c *AwinClient) {
+		c.SetLimiter(limiter)
+	}
+}
+
+// WithCache
+// / Same as AwinClient.SetCache.
+func WithCache(cache *DiskCache) Option {
+	return func(c *AwinClient) {
+		c.SetCache(cache)
+	}
+}
+
+// WithProgressObserver
+// / Same as AwinClient.SetProgressObserver.
+func WithProgressObserver(observer ProgressObserver, interval time.Duration) Option {
+	return func(c *AwinClient) {
+		c.SetProgressObserver(observer, interval)
+	}
+}
+
+// WithLenientDecoding
+// / Skips csv rows with broken quoting or a wrong number of fields instead of failing the whole feed.
+// / Feeds with a share of skipped rows above maxErrorRatio, between 0 and 1, fail with a *RowErrorRatioError.
+// / The skipped rows are returned by FetchDataFeedWithReport, DataFeedResult.Report and DataFeedReader.Report.
+func WithLenientDecoding(maxErrorRatio float64) Option {
+	return func(c *AwinClient) {
+		c.lenient = &lenientDecoding{maxErrorRatio: maxErrorRatio}
+	}
+}
+
+// WithSchemaDrift
+// / Controls how csv feeds are handled whose header has unknown columns or lacks expected ones, see SchemaDriftMode.
+// / The drift is always returned by DataFeedReader.SchemaDrift and in the DecodeReport, unknown columns end up in
+// / DataFeedEntry.Extra. Defaults to SchemaDriftIgnore.
+func WithSchemaDrift(mode SchemaDriftMode) Option {
+	return func(c *AwinClient) {
+		c.schemaDrift = mode
+	}
+}
+
+// WithDefaultDataFeedOptions
+// / Used by FetchDataFeed and StreamDataFeed if they are called with nil options.
+// / They also fill the empty fields of passed options, except ShowAdultContent: passed options decide on their own
+// / whether adult content is shown.
+func WithDefaultDataFeedOptions(options DataFeedOptions) Option {
+	return func(c *AwinClient) {
+		c.defaultOptions = options
+	}
+}
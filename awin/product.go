@@ -0,0 +1,220 @@
+package awin
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayouts are tried in order when parsing the date columns of a data feed
+var dateLayouts = []string{
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"1/2/2006 15:04:05",
+	"1/2/2006 15:04",
+	"1/2/2006",
+}
+
+var (
+	errInvalidCurrency = errors.New("invalid ISO 4217 currency code")
+	errInvalidBool     = errors.New("invalid boolean")
+	errInvalidDate     = errors.New("invalid date")
+	errInvalidInt      = errors.New("invalid integer")
+)
+
+// Product
+// / Typed companion of DataFeedEntry with parsed prices, dates, flags and numbers.
+// / Entry holds the raw entry for all columns that are plain text. Empty columns leave the zero value.
+type Product struct {
+	Entry DataFeedEntry
+
+	SearchPrice     Money
+	StorePrice      Money
+	RrpPrice        Money
+	DeliveryCost    Money
+	ProductPriceOld Money
+	Saving          Money
+	BasePriceAmount Money
+	SavingsPercent  Decimal
+
+	LastUpdated time.Time
+	ValidFrom   time.Time
+	ValidTo     time.Time
+
+	InStock   bool
+	IsForSale bool
+	WebOffer  bool
+	PreOrder  bool
+
+	StockQuantity   int
+	NumberAvailable int
+	Reviews         int
+	AverageRating   float64
+	Rating          float64
+}
+
+// FieldError
+// / Describes a column value that could not be parsed into its typed Product or DataFeed field.
+// / Column The name of the feed column, or of the data feed list column for DataFeed fields, e.g. "Last Imported"
+type FieldError struct {
+	Column string
+	Value  string
+	Err    error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("invalid value '%s' for column '%s': %v", e.Value, e.Column, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors
+// / All column values of one entry that could not be parsed.
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ToProduct
+// / Converts the entry into a Product. Columns that cannot be parsed keep their zero value and are reported as
+// / FieldErrors, the returned Product is never nil so callers can decide to use the partial result.
+func (e DataFeedEntry) ToProduct() (*Product, error) {
+	p := &Product{Entry: e}
+	parser := fieldParser{}
+
+	currency := strings.ToUpper(strings.TrimSpace(e.Currency))
+	if currency != "" && !isCurrencyCode(currency) {
+		parser.fail(string(ColumnCurrency), e.Currency, errInvalidCurrency)
+	}
+
+	p.SearchPrice = parser.money(string(ColumnSearchPrice), e.SearchPrice, currency)
+	p.StorePrice = parser.money(string(ColumnStorePrice), e.StorePrice, currency)
+	p.RrpPrice = parser.money(string(ColumnRrpPrice), e.RrpPrice, currency)
+	p.DeliveryCost = parser.money(string(ColumnDeliveryCost), e.DeliveryCost, currency)
+	p.ProductPriceOld = parser.money(string(ColumnProductPriceOld), e.ProductPriceOld, currency)
+	p.Saving = parser.money(string(ColumnSaving), e.Saving, currency)
+	p.BasePriceAmount = parser.money(string(ColumnBasePriceAmount), e.BasePriceAmount, currency)
+	p.SavingsPercent = parser.decimal(string(ColumnSavingsPercent), strings.TrimSuffix(strings.TrimSpace(e.SavingsPercent), "%"))
+
+	p.LastUpdated = parser.date(string(ColumnLastUpdated), e.LastUpdated)
+	p.ValidFrom = parser.date(string(ColumnValidFrom), e.ValidFrom)
+	p.ValidTo = parser.date(string(ColumnValidTo), e.ValidTo)
+
+	p.InStock = parser.bool(string(ColumnInStock), e.InStock)
+	p.IsForSale = parser.bool(string(ColumnIsForSale), e.IsForSale)
+	p.WebOffer = parser.bool(string(ColumnWebOffer), e.WebOffer)
+	p.PreOrder = parser.bool(string(ColumnPreOrder), e.PreOrder)
+
+	p.StockQuantity = parser.int(string(ColumnStockQuantity), e.StockQuantity)
+	p.NumberAvailable = parser.int(string(ColumnNumberAvailable), e.NumberAvailable)
+	p.Reviews = parser.int(string(ColumnReviews), e.Reviews)
+	p.AverageRating = parser.float(string(ColumnAverageRating), e.AverageRating)
+	p.Rating = parser.float(string(ColumnRating), e.Rating)
+
+	if len(parser.errs) > 0 {
+		return p, parser.errs
+	}
+	return p, nil
+}
+
+// fieldParser parses column values and collects a FieldError for every value it cannot parse
+type fieldParser struct {
+	errs FieldErrors
+}
+
+func (f *fieldParser) fail(column string, value string, err error) {
+	f.errs = append(f.errs, &FieldError{Column: column, Value: value, Err: err})
+}
+
+func (f *fieldParser) decimal(column string, value string) Decimal {
+	if strings.TrimSpace(value) == "" {
+		return Decimal{}
+	}
+	d, err := ParseDecimal(value)
+	if err != nil {
+		f.fail(column, value, err)
+	}
+	return d
+}
+
+func (f *fieldParser) money(column string, value string, currency string) Money {
+	if strings.TrimSpace(value) == "" {
+		return Money{}
+	}
+	return Money{Amount: f.decimal(column, value), Currency: currency}
+}
+
+func (f *fieldParser) date(column string, value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	f.fail(column, value, errInvalidDate)
+	return time.Time{}
+}
+
+func (f *fieldParser) bool(column string, value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "":
+		return false
+	case "1", "true", "yes", "y":
+		return true
+	case "0", "false", "no", "n":
+		return false
+	}
+	f.fail(column, value, errInvalidBool)
+	return false
+}
+
+func (f *fieldParser) int(column string, value string) int {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		f.fail(column, value, errInvalidInt)
+	}
+	return i
+}
+
+func (f *fieldParser) float(column string, value string) float64 {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	d, err := ParseDecimal(value)
+	if err != nil {
+		f.fail(column, value, err)
+	}
+	return d.Float64()
+}
+
+// isCurrencyCode checks the ISO 4217 format of three upper case letters
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
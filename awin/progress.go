@@ -0,0 +1,107 @@
+package awin
+
+import (
+	"time"
+)
+
+// DataFeedProgress
+// / State of a single feed download, reported to a ProgressObserver.
+// / FeedIds The feed ids of the download, empty for urls without fid
+// / Url The download url with the api key redacted
+// / BytesRead Downloaded bytes so far, for compressed feeds these are the compressed bytes
+// / ContentLength The announced size of the download in bytes, -1 if unknown
+// / Rows Entries decoded so far
+// / Elapsed Time since the request was sent
+// / Done true for the last report of the download
+// / Err The error that stopped the download, only set with Done. nil if the feed was read completely or closed early
+type DataFeedProgress struct {
+	FeedIds       []string
+	Url           string
+	BytesRead     int64
+	ContentLength int64
+	Rows          int
+	Elapsed       time.Duration
+	Done          bool
+	Err           error
+}
+
+// Percent
+// / Returns the downloaded share of ContentLength between 0 and 100, -1 if the length is unknown.
+func (p DataFeedProgress) Percent() float64 {
+	if p.ContentLength <= 0 {
+		return -1
+	}
+	percent := float64(p.BytesRead) * 100 / float64(p.ContentLength)
+	if percent > 100 {
+		percent = 100
+	}
+	return percent
+}
+
+// ProgressObserver
+// / Receives the progress of feed downloads, see AwinClient.SetProgressObserver.
+// / Reports of one download arrive in order on the goroutine reading the feed. FetchDataFeeds downloads feeds in
+// / parallel, so implementations must be safe for concurrent use.
+type ProgressObserver interface {
+	DataFeedProgress(progress DataFeedProgress)
+}
+
+// ProgressFunc
+// / Adapter to use a function as ProgressObserver.
+type ProgressFunc func(progress DataFeedProgress)
+
+// DataFeedProgress
+// / Calls f(progress).
+func (f ProgressFunc) DataFeedProgress(progress DataFeedProgress) {
+	f(progress)
+}
+
+// progressTracker reports the progress of one download, at most once per interval while entries are decoded
+type progressTracker struct {
+	observer ProgressObserver
+	interval time.Duration
+	counter  *countingReader
+	progress DataFeedProgress
+	start    time.Time
+	last     time.Time
+}
+
+func newProgressTracker(observer ProgressObserver, interval time.Duration, request dataFeedRequest, contentLength int64, start time.Time) *progressTracker {
+	return &progressTracker{
+		observer: observer,
+		interval: interval,
+		progress: DataFeedProgress{
+			FeedIds:       request.feedIds,
+			Url:           redactUrl(request.url),
+			ContentLength: contentLength,
+		},
+		start: start,
+	}
+}
+
+// row counts a decoded entry and reports if the interval has passed since the last report
+func (t *progressTracker) row() {
+	t.progress.Rows++
+	if time.Since(t.last) >= t.interval {
+		t.report()
+	}
+}
+
+// finish sends the final report, later calls are ignored
+func (t *progressTracker) finish(err error) {
+	if t.progress.Done {
+		return
+	}
+	t.progress.Done = true
+	t.progress.Err = err
+	t.report()
+}
+
+func (t *progressTracker) report() {
+	t.last = time.Now()
+	if t.counter != nil {
+		t.progress.BytesRead = t.counter.n
+	}
+	t.progress.Elapsed = t.last.Sub(t.start)
+	t.observer.DataFeedProgress(t.progress)
+}
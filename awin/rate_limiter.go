@@ -0,0 +1,130 @@
+package awin
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// Limiter
+// / Controls when the AwinClient may send a request. Acquire blocks until the request is allowed or ctx is done,
+// / release is called once the response body has been consumed and closed.
+type Limiter interface {
+	Acquire(ctx context.Context) (release func(), err error)
+}
+
+// RateLimiter
+// / Limiter allowing a number of requests per interval and a maximum of requests in flight.
+// / Share one RateLimiter across clients and goroutines to keep all of them within the same budget.
+type RateLimiter struct {
+	interval time.Duration
+	inFlight chan struct{}
+
+	mutex sync.Mutex
+	slots []time.Time
+	next  int
+}
+
+// NewRateLimiter
+// / Returns a RateLimiter allowing requests per interval, values below 1 disable the rate limit.
+// / maxInFlight caps the number of concurrent requests including their body downloads, values below 1 disable the cap.
+func NewRateLimiter(requests int, interval time.Duration, maxInFlight int) *RateLimiter {
+	l := &RateLimiter{interval: interval}
+	if requests > 0 && interval > 0 {
+		l.slots = make([]time.Time, requests)
+	}
+	if maxInFlight > 0 {
+		l.inFlight = make(chan struct{}, maxInFlight)
+	}
+	return l
+}
+
+// Acquire
+// / Waits for a free in-flight slot and then for the rate limit. The returned release frees the in-flight slot.
+func (l *RateLimiter) Acquire(ctx context.Context) (func(), error) {
+	release := func() {}
+	if l.inFlight != nil {
+		select {
+		case l.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		var once sync.Once
+		release = func() {
+			once.Do(func() { <-l.inFlight })
+		}
+	}
+
+	if err := l.wait(ctx); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// wait reserves the earliest time that keeps the last len(slots) requests within interval and sleeps until then.
+// The reservation is given back if ctx is done first.
+func (l *RateLimiter) wait(ctx context.Context) error {
+	if l.slots == nil {
+		return nil
+	}
+
+	l.mutex.Lock()
+	now := time.Now()
+	index := l.next
+	previous := l.slots[index]
+	at := previous.Add(l.interval)
+	if at.Before(now) {
+		at = now
+	}
+	l.slots[index] = at
+	l.next = (l.next + 1) % len(l.slots)
+	l.mutex.Unlock()
+
+	wait := time.Until(at)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel(index, previous, at)
+		return ctx.Err()
+	}
+}
+
+// cancel gives back the reservation at of slot index, previous is the time the slot held before
+func (l *RateLimiter) cancel(index int, previous time.Time, at time.Time) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if !l.slots[index].Equal(at) {
+		// the slot has been reserved again, later reservations already wait for it
+		return
+	}
+	if (l.next+len(l.slots)-1)%len(l.slots) == index {
+		// nothing was reserved after it, undo the reservation
+		l.slots[index] = previous
+		l.next = index
+		return
+	}
+	// later reservations keep their time, the slot only has to wait for the reservation before it
+	l.slots[index] = l.slots[(index+len(l.slots)-1)%len(l.slots)]
+}
+
+// releaseOnClose calls release once the wrapped body is closed
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
@@ -0,0 +1,282 @@
+package awin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RefreshStatus
+// / What RefreshDataFeeds did with a feed.
+type RefreshStatus int
+
+const (
+	// RefreshFetched The feed changed or was never fetched and has been downloaded
+	RefreshFetched RefreshStatus = iota
+	// RefreshSkippedUnchanged The feed list reports the same last import as the state
+	RefreshSkippedUnchanged
+	// RefreshSkippedNotModified Awin answered the conditional request with 304 Not Modified
+	RefreshSkippedNotModified
+	// RefreshSkippedNotJoined The publisher is no active member of the advertiser program
+	RefreshSkippedNotJoined
+	// RefreshFailed The download failed, the state of the feed is kept
+	RefreshFailed
+)
+
+func (s RefreshStatus) String() string {
+	switch s {
+	case RefreshFetched:
+		return "fetched"
+	case RefreshSkippedUnchanged:
+		return "unchanged"
+	case RefreshSkippedNotModified:
+		return "not modified"
+	case RefreshSkippedNotJoined:
+		return "not joined"
+	default:
+		return "failed"
+	}
+}
+
+// Skipped
+// / Returns true if the feed has not been downloaded because it did not change or cannot be downloaded.
+func (s RefreshStatus) Skipped() bool {
+	return s == RefreshSkippedUnchanged || s == RefreshSkippedNotModified || s == RefreshSkippedNotJoined
+}
+
+// DataFeedState
+// / What RefreshDataFeeds remembers about a feed between runs.
+// / LastImported, LastChecked The timestamps of the feed list at the last successful download
+// / ETag, LastModified The validators of the last download response, empty if Awin did not send them
+// / FetchedAt Time of the last successful download
+type DataFeedState struct {
+	LastImported time.Time `json:"last_imported"`
+	LastChecked  time.Time `json:"last_checked"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+}
+
+// RefreshState
+// / The persisted DataFeedState of all feeds by feed id. Feeds are compared by id only, use one state per set of
+// / DataFeedOptions so a change of columns or format is not skipped as unchanged.
+type RefreshState struct {
+	Feeds map[string]DataFeedState `json:"feeds"`
+}
+
+// NewRefreshState
+// / Returns an empty state, RefreshDataFeeds downloads every feed once.
+func NewRefreshState() *RefreshState {
+	return &RefreshState{Feeds: map[string]DataFeedState{}}
+}
+
+// LoadRefreshState
+// / Reads a state written by Save, a missing file returns an empty state.
+func LoadRefreshState(path string) (*RefreshState, error) {
+	content, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewRefreshState(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state := NewRefreshState()
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("invalid refresh state '%s': %w", path, err)
+	}
+	if state.Feeds == nil {
+		state.Feeds = map[string]DataFeedState{}
+	}
+	return state, nil
+}
+
+// Save
+// / Writes the state as json to path. The file is replaced atomically, a crash never leaves a partial state.
+func (s *RefreshState) Save(path string) error {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// RefreshResult
+// / Outcome of a feed refreshed by RefreshDataFeeds. Entries is only set for RefreshFetched, Err for RefreshFailed.
+// / Reason explains why the feed was skipped or fetched.
+type RefreshResult struct {
+	DataFeedResult
+	Status RefreshStatus
+	Reason string
+}
+
+// RefreshDataFeeds
+// / Downloads only the feeds of options.FeedIds that changed since the last run, all joined feeds of the feed list
+// / if options.FeedIds is empty. A feed is skipped if the feed list reports the same Last Imported as state, or if
+// / Awin answers If-None-Match/If-Modified-Since with 304. Fetched feeds update state, the caller persists it.
+// / Returns an error if the feed list cannot be fetched, failures of single feeds are reported in their result.
+// / A nil state downloads every feed once without remembering anything. The feed list and the feed downloads
+// / bypass the DiskCache.
+func (c AwinClient) RefreshDataFeeds(state *RefreshState, options *DataFeedOptions, workers int) ([]RefreshResult, error) {
+	return c.RefreshDataFeedsWithContext(context.Background(), state, options, workers)
+}
+
+// RefreshDataFeedsWithContext
+// / Same as RefreshDataFeeds, feeds not finished when ctx is cancelled report ctx.Err() and keep their state.
+func (c AwinClient) RefreshDataFeedsWithContext(ctx context.Context, state *RefreshState, options *DataFeedOptions, workers int) ([]RefreshResult, error) {
+	options = c.dataFeedOptions(options)
+	if state == nil {
+		state = NewRefreshState()
+	}
+	if state.Feeds == nil {
+		state.Feeds = map[string]DataFeedState{}
+	}
+
+	// A cached feed list would report outdated timestamps and skip changed feeds
+	rows, err := c.fetchDataFeedList(ctx, noCacheHeader())
+	if err != nil {
+		return nil, err
+	}
+
+	feeds := make(map[string]*DataFeed, len(*rows))
+	var joined []string
+	for _, row := range *rows {
+		// Unparseable columns keep their zero value and are compared as unknown
+		feed, _ := row.ToDataFeed()
+		feeds[row.FeedID] = feed
+		if feed.IsJoined() {
+			joined = append(joined, row.FeedID)
+		}
+	}
+
+	feedIds := options.FeedIds
+	if len(feedIds) == 0 {
+		feedIds = joined
+	}
+
+	results := make([]RefreshResult, len(feedIds))
+	updates := make([]*DataFeedState, len(feedIds))
+	runFeedWorkers(len(feedIds), workers, func(i int) {
+		results[i], updates[i] = c.refreshDataFeed(ctx, options, feedIds[i], feeds[feedIds[i]], state.Feeds[feedIds[i]])
+	})
+
+	// The state is only written here, the workers just read it
+	for i, update := range updates {
+		if update != nil {
+			state.Feeds[feedIds[i]] = *update
+		}
+		c.logger.Info("awin feed refreshed", "feed_id", feedIds[i], "status", results[i].Status.String(), "reason", results[i].Reason)
+	}
+
+	return results, nil
+}
+
+// refreshDataFeed downloads feedId unless feed or the previous state tell it did not change. feed is nil if the
+// feed is missing in the feed list. Returns the new state of the feed, nil to keep the previous one.
+func (c AwinClient) refreshDataFeed(ctx context.Context, options *DataFeedOptions, feedId string, feed *DataFeed, previous DataFeedState) (RefreshResult, *DataFeedState) {
+	result := RefreshResult{DataFeedResult: DataFeedResult{FeedId: feedId}}
+
+	if feed != nil && !feed.IsJoined() {
+		result.Status = RefreshSkippedNotJoined
+		result.Reason = fmt.Sprintf("membership status is %s", feed.MembershipStatus)
+		return result, nil
+	}
+
+	if feed != nil && !feed.LastImported.IsZero() && feed.LastImported.Equal(previous.LastImported) {
+		result.Status = RefreshSkippedUnchanged
+		result.Reason = fmt.Sprintf("last imported %s, last checked %s", feed.LastImported.Format(time.RFC3339), feed.LastChecked.Format(time.RFC3339))
+
+		updated := previous
+		updated.LastChecked = feed.LastChecked
+		return result, &updated
+	}
+
+	start := time.Now()
+	feedOptions := *options
+	feedOptions.FeedIds = []string{feedId}
+
+	request, err := c.dataFeedRequest(&feedOptions)
+	if err != nil {
+		result.Status, result.Reason, result.Err = RefreshFailed, err.Error(), err
+		return result, nil
+	}
+	request.header = conditionalHeader(previous)
+
+	download, err := c.fetchDataFeedDownload(ctx, request)
+	result.Bytes, result.Report, result.Duration = download.bytes, download.report, time.Since(start)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotModified {
+		result.Status = RefreshSkippedNotModified
+		result.Reason = "not modified since the last download"
+
+		updated := previous
+		if feed != nil {
+			updated.LastImported, updated.LastChecked = feed.LastImported, feed.LastChecked
+		}
+		return result, &updated
+	}
+	if err != nil {
+		result.Status, result.Reason, result.Err = RefreshFailed, err.Error(), err
+		return result, nil
+	}
+
+	result.Status = RefreshFetched
+	result.Entries = download.entries
+	switch {
+	case feed == nil:
+		result.Reason = "feed is missing in the feed list"
+	case previous.FetchedAt.IsZero():
+		result.Reason = "never fetched before"
+	default:
+		result.Reason = fmt.Sprintf("last imported changed from %s to %s", previous.LastImported.Format(time.RFC3339), feed.LastImported.Format(time.RFC3339))
+	}
+
+	updated := DataFeedState{
+		ETag:         download.header.Get("ETag"),
+		LastModified: download.header.Get("Last-Modified"),
+		FetchedAt:    time.Now(),
+	}
+	if feed != nil {
+		updated.LastImported, updated.LastChecked = feed.LastImported, feed.LastChecked
+	}
+	return result, &updated
+}
+
+// conditionalHeader returns the conditional request headers for the validators of the previous download. The cache
+// is bypassed, cached bodies carry no validators for the next run.
+func conditionalHeader(previous DataFeedState) http.Header {
+	header := noCacheHeader()
+	if previous.ETag != "" {
+		header.Set("If-None-Match", previous.ETag)
+	}
+	if previous.LastModified != "" {
+		header.Set("If-Modified-Since", previous.LastModified)
+	}
+	return header
+}
+
+// noCacheHeader returns the request header bypassing the DiskCache
+func noCacheHeader() http.Header {
+	return http.Header{"Cache-Control": {"no-cache"}}
+}
@@ -0,0 +1,183 @@
+package awin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var (
+	jitterRand  = rand.New(rand.NewSource(time.Now().UnixNano()))
+	jitterMutex sync.Mutex
+)
+
+// RetryPolicy
+// / Controls how often and how fast failed requests are retried.
+// / MaxAttempts Number of attempts including the first one, values below 2 disable retries
+// / InitialBackoff Wait time before the first retry
+// / MaxBackoff Upper bound for the exponential backoff
+// / MaxRetryAfter Longest Retry-After header that is waited for, MaxBackoff if below 1. Responses asking for a longer
+// / wait are not retried and return their *APIError, a single Retry-After of a day must not block the caller
+// / Multiplier Factor the backoff grows with after every attempt
+// / Jitter Fraction between 0 and 1 of the backoff that is randomized, spreads retries of parallel importers
+// / RetryableStatusCodes Status codes that are retried, DefaultRetryableStatusCodes if nil
+// / RetryableError Decides if a transport or body read error is retried, IsRetryableError if nil
+type RetryPolicy struct {
+	MaxAttempts          int
+	InitialBackoff       time.Duration
+	MaxBackoff           time.Duration
+	MaxRetryAfter        time.Duration
+	Multiplier           float64
+	Jitter               float64
+	RetryableStatusCodes []int
+	RetryableError       func(err error) bool
+}
+
+// DefaultRetryableStatusCodes are the status codes Awin uses for transient failures
+var DefaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryPolicy
+// / Returns a policy with 4 attempts, starting at 1s backoff and doubling up to 30s with 20% jitter.
+// / Retry-After headers are honored up to 2 minutes.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+		MaxRetryAfter:  2 * time.Minute,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// IsRetryableError
+// / Reports whether err is a transient network failure, like a connection reset or a timeout, or a truncated body.
+func IsRetryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// retryable reports whether the attempt that failed with err should be repeated
+func (p RetryPolicy) retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		statusCodes := p.RetryableStatusCodes
+		if statusCodes == nil {
+			statusCodes = DefaultRetryableStatusCodes
+		}
+		for _, statusCode := range statusCodes {
+			if statusCode == apiErr.StatusCode {
+				return true
+			}
+		}
+		return false
+	}
+
+	if p.RetryableError != nil {
+		return p.RetryableError(err)
+	}
+	return IsRetryableError(err)
+}
+
+// retryAfterTooLong reports whether err asks to wait longer than the policy allows
+func (p RetryPolicy) retryAfterTooLong(err error) bool {
+	limit := p.MaxRetryAfter
+	if limit < 1 {
+		limit = p.MaxBackoff
+	}
+
+	var apiErr *APIError
+	return limit > 0 && errors.As(err, &apiErr) && apiErr.RetryAfter > limit
+}
+
+// backoff returns the wait time after the given failed attempt, starting at 1
+func (p RetryPolicy) backoff(attempt int, err error) time.Duration {
+	backoff := float64(p.InitialBackoff)
+	if p.Multiplier > 0 {
+		backoff *= math.Pow(p.Multiplier, float64(attempt-1))
+	}
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		jitterMutex.Lock()
+		backoff += backoff * p.Jitter * (2*jitterRand.Float64() - 1)
+		jitterMutex.Unlock()
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > time.Duration(backoff) {
+		return apiErr.RetryAfter
+	}
+	return time.Duration(backoff)
+}
+
+// retry calls attempt until it succeeds, fails with an error the policy does not retry or runs out of attempts
+func (c AwinClient) retry(ctx context.Context, endpoint Endpoint, attempt func() error) error {
+	for i := 1; ; i++ {
+		err := attempt()
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if c.retryPolicy == nil || i >= c.retryPolicy.MaxAttempts || !c.retryPolicy.retryable(err) {
+			return err
+		}
+		if c.retryPolicy.retryAfterTooLong(err) {
+			c.logger.Warn("awin retry after too long", "endpoint", string(endpoint), "attempt", i, "error", err)
+			return err
+		}
+
+		backoff := c.retryPolicy.backoff(i, err)
+		c.metrics.ObserveRetry(endpoint)
+		c.logger.Warn("awin retry", "endpoint", string(endpoint), "attempt", i, "max_attempts", c.retryPolicy.MaxAttempts, "backoff", backoff, "error", err)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// parseRetryAfter supports both forms of the Retry-After header, delay seconds and http dates
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
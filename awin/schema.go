@@ -0,0 +1,82 @@
+package awin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SchemaDriftMode
+// / Controls how the AwinClient handles csv feeds whose header differs from the expected columns, see WithSchemaDrift.
+type SchemaDriftMode int
+
+const (
+	// SchemaDriftIgnore Unknown columns are captured in DataFeedEntry.Extra and the drift is only reported
+	SchemaDriftIgnore SchemaDriftMode = iota
+	// SchemaDriftWarn Like SchemaDriftIgnore, the drift is also logged at warn level
+	SchemaDriftWarn
+	// SchemaDriftStrict Downloads with drift fail with a *SchemaDriftError before the first entry
+	SchemaDriftStrict
+)
+
+// SchemaDrift
+// / Difference between the header of a csv feed and the expected columns, all requested columns or all columns of
+// / DataFeedColumns if none were requested.
+// / Unknown Columns of the feed without a DataFeedEntry field that were not requested, in feed order. Their values end
+// / up in Extra if no columns were requested
+// / Missing Expected columns the feed does not contain, in the order of the expected columns
+type SchemaDrift struct {
+	Unknown []string
+	Missing []DataFeedColumn
+}
+
+// Empty
+// / Reports whether the header matches the expected columns.
+func (d SchemaDrift) Empty() bool {
+	return len(d.Unknown) == 0 && len(d.Missing) == 0
+}
+
+func (d SchemaDrift) String() string {
+	missing := make([]string, len(d.Missing))
+	for i, column := range d.Missing {
+		missing[i] = string(column)
+	}
+	return fmt.Sprintf("unknown columns [%s], missing columns [%s]", strings.Join(d.Unknown, ", "), strings.Join(missing, ", "))
+}
+
+// SchemaDriftError
+// / Returned by downloads of a client with SchemaDriftStrict if the feed header differs from the expected columns.
+type SchemaDriftError struct {
+	Drift SchemaDrift
+}
+
+func (e *SchemaDriftError) Error() string {
+	return fmt.Sprintf("data feed schema drift: %s", e.Drift)
+}
+
+// detectSchemaDrift compares header to the expected columns, all columns if expected is empty
+func detectSchemaDrift(header []string, expected []DataFeedColumn) SchemaDrift {
+	if len(expected) == 0 {
+		expected = dataFeedColumns
+	}
+
+	requested := make(map[string]bool, len(expected))
+	for _, column := range expected {
+		requested[string(column)] = true
+	}
+
+	var drift SchemaDrift
+	received := make(map[string]bool, len(header))
+	for _, column := range header {
+		column = strings.TrimSpace(column)
+		received[column] = true
+		if _, ok := dataFeedEntryFields[column]; !ok && !requested[column] {
+			drift.Unknown = append(drift.Unknown, column)
+		}
+	}
+	for _, column := range expected {
+		if !received[string(column)] {
+			drift.Missing = append(drift.Missing, column)
+		}
+	}
+	return drift
+}
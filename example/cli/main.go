@@ -1,23 +1,29 @@
 package main
 
 import (
+	"bufio"
+	"compress/gzip"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"github.com/matthiasbruns/awin-go/awin"
+	"github.com/matthiasbruns/awin-go/awin/awintest"
+	"io"
 	"net/http"
 	"os"
 	"strings"
 )
 
-const cliUsage = "expected 'feedlist' or 'feed' subcommands"
+const cliUsage = "expected 'feedlist', 'feed' or 'generate' subcommands"
 const feedListUsage = "./awin-go feedlist -apikey=API_KEY"
 const feedUsage = "./awin-go feed -apikey=API_KEY -ids id1 id2 -lang en -adult true"
+const generateUsage = "./awin-go generate -type feed -rows 1000000 -seed 1 -delimiter comma -unicode -messy -missing 0 -gzip -out feed.csv.gz"
 
 func main() {
 
 	feedListCmd := flag.NewFlagSet("feedlist", flag.ExitOnError)
 	feedCmd := flag.NewFlagSet("feed", flag.ExitOnError)
+	generateCmd := flag.NewFlagSet("generate", flag.ExitOnError)
 
 	if len(os.Args) < 2 {
 		fmt.Println(cliUsage)
@@ -29,6 +35,8 @@ func main() {
 		handleFeedListCmd(feedListCmd)
 	case "feed":
 		handleFeedCmd(feedCmd)
+	case "generate":
+		handleGenerateCmd(generateCmd)
 	default:
 		fmt.Println(cliUsage)
 		os.Exit(1)
@@ -95,3 +103,83 @@ func handleFeedCmd(feedListCmd *flag.FlagSet) {
 		fmt.Print(string(j))
 	}
 }
+
+func handleGenerateCmd(generateCmd *flag.FlagSet) {
+	feedType := generateCmd.String("type", "feed", "-type feed or -type list")
+	rows := generateCmd.Int("rows", 1000, "-rows 1000")
+	seed := generateCmd.Int64("seed", 1, "-seed 1")
+	delimiter := generateCmd.String("delimiter", "comma", "-delimiter comma, pipe or tab")
+	unicode := generateCmd.Bool("unicode", false, "-unicode")
+	messy := generateCmd.Bool("messy", false, "-messy")
+	missing := generateCmd.Int("missing", 0, "-missing 3")
+	compress := generateCmd.Bool("gzip", false, "-gzip")
+	out := generateCmd.String("out", "", "-out feed.csv, stdout if empty")
+
+	if err := generateCmd.Parse(os.Args[2:]); err != nil {
+		fmt.Print(generateUsage)
+		os.Exit(1)
+	}
+
+	delimiters := map[string]awin.DataFeedDelimiter{
+		"comma": awin.DelimiterComma,
+		"pipe":  awin.DelimiterPipe,
+		"tab":   awin.DelimiterTab,
+	}
+	if _, ok := delimiters[*delimiter]; !ok || (*feedType != "feed" && *feedType != "list") {
+		fmt.Print(generateUsage)
+		os.Exit(1)
+	}
+
+	options := awintest.GeneratorOptions{
+		Seed:           *seed,
+		Rows:           *rows,
+		Delimiter:      delimiters[*delimiter],
+		Unicode:        *unicode,
+		Messy:          *messy,
+		MissingColumns: *missing,
+	}
+
+	var w io.Writer = os.Stdout
+	var file *os.File
+	if *out != "" {
+		var err error
+		file, err = os.Create(*out)
+		if err != nil {
+			fmt.Print(err)
+			os.Exit(1)
+		}
+		w = file
+	}
+
+	buffered := bufio.NewWriter(w)
+	w = buffered
+	var gz *gzip.Writer
+	if *compress {
+		gz = gzip.NewWriter(buffered)
+		w = gz
+	}
+
+	var err error
+	if *feedType == "list" {
+		err = awintest.WriteDataFeedListCsv(w, options)
+	} else {
+		err = awintest.WriteDataFeedCsv(w, options)
+	}
+	if err == nil && gz != nil {
+		err = gz.Close()
+	}
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if file != nil {
+		// Close reports write errors of the file system, e.g. a full disk
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	if err != nil {
+		fmt.Print(err)
+		os.Exit(1)
+	}
+}
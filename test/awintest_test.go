@@ -0,0 +1,241 @@
+package awin_go
+
+import (
+	"context"
+	"errors"
+	"github.com/matthiasbruns/awin-go/awin"
+	"github.com/matthiasbruns/awin-go/awin/awintest"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func fakeServer(t *testing.T) (*awintest.Server, []awin.DataFeedEntry) {
+	csvContent, err := readCSVFileContents("testdata/data_feed.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+	rows, _ := parseCSVToDataFeedEntry(csvContent)
+
+	server := awintest.NewServer("apiKey")
+	server.AddFeed(awin.DataFeedListRow{FeedID: "1", FeedName: "Shoes"}, (*rows)[:6])
+	server.AddFeed(awin.DataFeedListRow{FeedID: "2", FeedName: "Shirts", MembershipStatus: "Not Joined"}, (*rows)[6:])
+	return server, *rows
+}
+
+func TestFakeServerFeedList(t *testing.T) {
+	server, _ := fakeServer(t)
+	defer server.Close()
+
+	rows, err := server.Client().FetchDataFeedList()
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if len(*rows) != 2 || (*rows)[0].MembershipStatus != "active" || (*rows)[1].FeedName != "Shirts" {
+		t.Fatalf("Invalid feed list received %v", *rows)
+	}
+
+	var apiErr *awin.APIError
+	if _, err := awin.New("wrongKey", awin.WithBaseUrl(server.URL)).FetchDataFeedList(); !errors.As(err, &apiErr) || apiErr.Kind != awin.ErrorKindAuth {
+		t.Fatalf("expected auth error, received '%v'", err)
+	}
+}
+
+func TestFakeServerFeedFormats(t *testing.T) {
+	server, entries := fakeServer(t)
+	defer server.Close()
+
+	for _, options := range []awin.DataFeedOptions{
+		{Format: awin.FormatCsv},
+		{Format: awin.FormatCsv, Delimiter: awin.DelimiterPipe, Compression: awin.CompressionZip},
+		{Format: awin.FormatCsv, Delimiter: awin.DelimiterTab, Compression: awin.CompressionNone},
+		{Format: awin.FormatJson},
+		{Format: awin.FormatXml, Compression: awin.CompressionNone},
+		{Format: awin.FormatXmlTree, Compression: awin.CompressionNone},
+	} {
+		options.FeedIds = []string{"1", "2"}
+		options.Language = "en"
+
+		result, err := server.Client().FetchDataFeed(&options)
+		if err != nil {
+			t.Fatalf("%v: err is not null '%v'", options, err)
+		}
+		if len(*result) != len(entries) {
+			t.Fatalf("%v: Invalid amount of data rows received %d", options, len(*result))
+		}
+		for i, expectedRow := range entries {
+			if !reflect.DeepEqual(expectedRow, (*result)[i]) {
+				t.Fatalf("%v: Invalid row parsed\nexpected '%v'\nreceived '%v'", options, expectedRow, (*result)[i])
+			}
+		}
+	}
+
+	// Only the requested columns are served
+	result, err := server.Client().FetchDataFeed(&awin.DataFeedOptions{
+		FeedIds:  []string{"1"},
+		Language: "en",
+		Columns:  []awin.DataFeedColumn{awin.ColumnAwProductId, awin.ColumnSearchPrice},
+	})
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	expectedRow := awin.DataFeedEntry{AwProductId: entries[0].AwProductId, SearchPrice: entries[0].SearchPrice}
+	if len(*result) != 6 || !reflect.DeepEqual((*result)[0], expectedRow) {
+		t.Fatalf("Invalid row parsed\nexpected '%v'\nreceived '%v'", expectedRow, (*result)[0])
+	}
+
+	requests := server.Requests()
+	if len(requests) != 7 || requests[6].FeedUrl == nil || len(requests[6].FeedUrl.Columns) != 2 {
+		t.Fatalf("Invalid requests recorded %v", requests)
+	}
+}
+
+func TestFakeServerXmlTree(t *testing.T) {
+	server := awintest.NewServer("apiKey")
+	defer server.Close()
+	server.AddFeed(awin.DataFeedListRow{FeedID: "1"}, []awin.DataFeedEntry{
+		{AwProductId: "1", MerchantId: "10", MerchantName: "Shop", ProductName: "Shoe", SearchPrice: "9.99", Currency: "EUR"},
+		{AwProductId: "2", MerchantId: "10", MerchantName: "Shop", ProductName: "Boot", SearchPrice: "19.99", Currency: "EUR"},
+		{AwProductId: "3", MerchantId: "20", MerchantName: "Other", ProductName: "Shirt", SearchPrice: "5", Currency: "GBP"},
+	})
+
+	columns := []awin.DataFeedColumn{awin.ColumnAwProductId, awin.ColumnMerchantId, awin.ColumnMerchantName, awin.ColumnProductName, awin.ColumnSearchPrice, awin.ColumnCurrency}
+	feedUrl := awin.NewDataFeedURL("apiKey", awin.DataFeedOptions{FeedIds: []string{"1"}, Language: "en", Columns: columns, Format: awin.FormatXmlTree, Compression: awin.CompressionNone})
+	feedUrl.BaseUrl = server.URL
+
+	resp, err := http.Get(feedUrl.String())
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	// Products are grouped by merchant, ids and currencies are attributes
+	expected := `<merchantProductFeed><merchant id="10" name="Shop"><prod id="1"><text><name>Shoe</name></text><price curr="EUR"><buynow>9.99</buynow></price></prod>` +
+		`<prod id="2"><text><name>Boot</name></text><price curr="EUR"><buynow>19.99</buynow></price></prod></merchant>` +
+		`<merchant id="20" name="Other"><prod id="3"><text><name>Shirt</name></text><price curr="GBP"><buynow>5</buynow></price></prod></merchant></merchantProductFeed>`
+	if string(body) != expected {
+		t.Fatalf("Invalid tree xml\nexpected '%s'\nreceived '%s'", expected, body)
+	}
+
+	result, err := server.Client().FetchDataFeedFromUrl(feedUrl.String())
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if len(*result) != 3 || !reflect.DeepEqual((*result)[2], awin.DataFeedEntry{AwProductId: "3", MerchantId: "20", MerchantName: "Other", ProductName: "Shirt", SearchPrice: "5", Currency: "GBP"}) {
+		t.Fatalf("Invalid entries parsed %v", *result)
+	}
+}
+
+func TestFakeServerCustomColumns(t *testing.T) {
+	server := awintest.NewServer("apiKey")
+	defer server.Close()
+	server.AddFeed(awin.DataFeedListRow{FeedID: "1"}, []awin.DataFeedEntry{
+		{AwProductId: "1", Extra: map[string]string{"Fashion:size": "L", "Fashion:fit": "slim"}},
+	})
+
+	for _, format := range []awin.DataFeedFormat{awin.FormatCsv, awin.FormatJson, awin.FormatXml, awin.FormatXmlTree} {
+		result, err := server.Client().FetchDataFeedFromUrl(server.URL + "/datafeed/download/apikey/apiKey/language/en/fid/1/columns/aw_product_id,Fashion:size/format/" + string(format) + "/")
+		if err != nil {
+			t.Fatalf("%s: err is not null '%v'", format, err)
+		}
+		expected := awin.DataFeedEntry{AwProductId: "1", Extra: map[string]string{"Fashion:size": "L"}}
+		if len(*result) != 1 || !reflect.DeepEqual((*result)[0], expected) {
+			t.Fatalf("%s: Invalid entries parsed %v", format, *result)
+		}
+	}
+
+	// Options request custom columns as well
+	result, err := server.Client().FetchDataFeed(&awin.DataFeedOptions{FeedIds: []string{"1"}, Language: "en", Columns: []awin.DataFeedColumn{awin.ColumnAwProductId, "Fashion:size"}})
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if len(*result) != 1 || !reflect.DeepEqual((*result)[0].Extra, map[string]string{"Fashion:size": "L"}) {
+		t.Fatalf("Invalid entries parsed %v", *result)
+	}
+}
+
+func TestFakeServerValidatesUrl(t *testing.T) {
+	server, _ := fakeServer(t)
+	defer server.Close()
+	awinClient := server.Client()
+
+	for _, test := range []struct {
+		url        string
+		statusCode int
+	}{
+		{server.URL + "/datafeed/download/apikey/apiKey/language/en/fid/3/", http.StatusNotFound},
+		{server.URL + "/datafeed/download/apikey/apiKey/fid/1/", http.StatusBadRequest},
+		{server.URL + "/datafeed/download/apikey/apiKey/language/en/fid/1/columns/unknown_column/", http.StatusBadRequest},
+		{server.URL + "/datafeed/download/apikey/otherKey/language/en/fid/1/", http.StatusUnauthorized},
+	} {
+		var apiErr *awin.APIError
+		if _, err := awinClient.FetchDataFeedFromUrl(test.url); !errors.As(err, &apiErr) || apiErr.StatusCode != test.statusCode {
+			t.Fatalf("expected status %d for '%s', received '%v'", test.statusCode, test.url, err)
+		}
+	}
+}
+
+func TestFakeServerFaults(t *testing.T) {
+	server, _ := fakeServer(t)
+	defer server.Close()
+	options := &awin.DataFeedOptions{FeedIds: []string{"1"}, Language: "en"}
+
+	// A single 503 is retried
+	server.AddFault(awintest.Fault{StatusCode: http.StatusServiceUnavailable, Times: 1})
+	if _, err := server.Client(awin.WithRetryPolicy(testRetryPolicy())).FetchDataFeed(options); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	// A truncated gzip body is an error without retries
+	server.AddFault(awintest.Fault{FeedId: "1", TruncateAfter: 100, Times: 1})
+	if _, err := server.Client().FetchDataFeed(options); err == nil {
+		t.Fatal("expected error for truncated body")
+	}
+
+	// A slow body runs into the deadline
+	server.AddFault(awintest.Fault{Delay: 50 * time.Millisecond, Times: 1})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := server.Client().FetchDataFeedWithContext(ctx, options); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, received '%v'", err)
+	}
+
+	// Faults of the feed list do not affect downloads
+	server.AddFault(awintest.Fault{List: true, StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"10"}}})
+	var apiErr *awin.APIError
+	if _, err := server.Client().FetchDataFeedList(); !errors.As(err, &apiErr) || apiErr.RetryAfter != 10*time.Second {
+		t.Fatalf("expected rate limit error, received '%v'", err)
+	}
+	if _, err := server.Client().FetchDataFeed(options); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+}
+
+func TestFakeServerNotModified(t *testing.T) {
+	server, _ := fakeServer(t)
+	defer server.Close()
+	awinClient := server.Client()
+
+	state := awin.NewRefreshState()
+	for i, expected := range []awin.RefreshStatus{awin.RefreshFetched, awin.RefreshSkippedNotModified} {
+		// Forget the last import so only the ETag prevents the download
+		for feedId, feedState := range state.Feeds {
+			feedState.LastImported = time.Time{}
+			state.Feeds[feedId] = feedState
+		}
+
+		results, err := awinClient.RefreshDataFeeds(state, &awin.DataFeedOptions{Language: "en"}, 1)
+		if err != nil {
+			t.Fatalf("err is not null '%v'", err)
+		}
+		if len(results) != 1 || results[0].Status != expected {
+			t.Fatalf("Invalid results of run %d: %v", i, results)
+		}
+	}
+}
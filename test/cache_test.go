@@ -0,0 +1,229 @@
+package awin_go
+
+import (
+	"github.com/matthiasbruns/awin-go/awin"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// cacheTestServer serves the feed list and the testdata feed and counts the requests by path
+func cacheTestServer(t *testing.T) (*httptest.Server, func(path string) int) {
+	csvContent, err := readCSVFileContents("testdata/data_feed.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+	listContent, err := readCSVFileContents("testdata/data_feed_list.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+	feedContent := gzipContent(t, csvContent)
+
+	var mutex sync.Mutex
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests[r.URL.Path]++
+		mutex.Unlock()
+
+		if strings.Contains(r.URL.Path, "/datafeed/list/") {
+			_, _ = w.Write([]byte(listContent))
+			return
+		}
+		_, _ = w.Write([]byte(feedContent))
+	}))
+
+	count := func(path string) int {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		total := 0
+		for requestPath, n := range requests {
+			if strings.Contains(requestPath, path) {
+				total += n
+			}
+		}
+		return total
+	}
+	return server, count
+}
+
+func cacheDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "awin-cache")
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	return dir
+}
+
+func TestDiskCache(t *testing.T) {
+	server, requests := cacheTestServer(t)
+	defer server.Close()
+	dir := cacheDir(t)
+	defer os.RemoveAll(dir)
+
+	cache, err := awin.NewDiskCache(dir, time.Hour, 0)
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	awinClient := awin.New("secretApiKey", awin.WithBaseUrl(server.URL), awin.WithCache(cache))
+	options := &awin.DataFeedOptions{FeedIds: []string{"fid1"}, Language: "en"}
+
+	for i := 0; i < 3; i++ {
+		feed, err := awinClient.FetchDataFeed(options)
+		if err != nil {
+			t.Fatalf("err is not null '%v'", err)
+		}
+		if len(*feed) != 10 {
+			t.Fatalf("Invalid amount of data rows received %d", len(*feed))
+		}
+
+		list, err := awinClient.FetchDataFeedList()
+		if err != nil {
+			t.Fatalf("err is not null '%v'", err)
+		}
+		if len(*list) != 10 {
+			t.Fatalf("Invalid amount of data rows received %d", len(*list))
+		}
+	}
+
+	if requests("/datafeed/download/") != 1 || requests("/datafeed/list/") != 1 {
+		t.Fatalf("Invalid amount of requests %d %d", requests("/datafeed/download/"), requests("/datafeed/list/"))
+	}
+
+	// Another feed is a different entry
+	if _, err := awinClient.FetchDataFeed(&awin.DataFeedOptions{FeedIds: []string{"fid2"}, Language: "en"}); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if requests("/datafeed/download/") != 2 {
+		t.Fatalf("Invalid amount of requests %d", requests("/datafeed/download/"))
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("Invalid amount of cache files %d", len(files))
+	}
+	for _, file := range files {
+		if strings.Contains(file.Name(), "secretApiKey") || strings.HasSuffix(file.Name(), ".tmp") {
+			t.Fatalf("Invalid cache file '%s'", file.Name())
+		}
+	}
+
+	if err := cache.Clear(); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if _, err := awinClient.FetchDataFeed(options); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if requests("/datafeed/download/") != 3 {
+		t.Fatalf("Invalid amount of requests %d", requests("/datafeed/download/"))
+	}
+}
+
+func TestDiskCacheExpiryAndSizeLimit(t *testing.T) {
+	server, requests := cacheTestServer(t)
+	defer server.Close()
+
+	for _, test := range []struct {
+		name     string
+		ttl      time.Duration
+		maxBytes int64
+	}{
+		{"expired", time.Nanosecond, 0},
+		{"too large", time.Hour, 10},
+	} {
+		dir := cacheDir(t)
+		defer os.RemoveAll(dir)
+
+		cache, err := awin.NewDiskCache(dir, test.ttl, test.maxBytes)
+		if err != nil {
+			t.Fatalf("err is not null '%v'", err)
+		}
+		awinClient := awin.New("apiKey", awin.WithBaseUrl(server.URL), awin.WithCache(cache))
+
+		before := requests("/datafeed/list/")
+		for i := 0; i < 2; i++ {
+			if _, err := awinClient.FetchDataFeedList(); err != nil {
+				t.Fatalf("err is not null '%v'", err)
+			}
+		}
+		if requests("/datafeed/list/")-before != 2 {
+			t.Fatalf("%s: entry was served from cache", test.name)
+		}
+	}
+}
+
+func TestDiskCacheIgnoresIncompleteDownloads(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// Announce more bytes than sent, the client sees a truncated body
+		w.Header().Set("Content-Length", "100")
+		_, _ = w.Write([]byte("Advertiser ID,Feed ID\n1,"))
+	}))
+	defer server.Close()
+	dir := cacheDir(t)
+	defer os.RemoveAll(dir)
+
+	cache, err := awin.NewDiskCache(dir, time.Hour, 0)
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	awinClient := awin.New("apiKey", awin.WithBaseUrl(server.URL), awin.WithCache(cache))
+
+	for i := 0; i < 2; i++ {
+		if _, err := awinClient.FetchDataFeedList(); err == nil {
+			t.Fatal("expected error for truncated body")
+		}
+	}
+	if requests != 2 {
+		t.Fatalf("Invalid amount of requests %d", requests)
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 0 {
+		t.Fatalf("Invalid amount of cache files %d", len(files))
+	}
+}
+
+func TestDiskCachePruneRemovesStaleTempFiles(t *testing.T) {
+	dir := cacheDir(t)
+	defer os.RemoveAll(dir)
+
+	cache, err := awin.NewDiskCache(dir, 0, 0)
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	// Left over by an interrupted download and one still being written
+	stale := filepath.Join(dir, "download-1.tmp")
+	running := filepath.Join(dir, "download-2.tmp")
+	for _, path := range []string{stale, running} {
+		if err := ioutil.WriteFile(path, []byte("Advertiser ID"), 0600); err != nil {
+			t.Fatalf("err is not null '%v'", err)
+		}
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	if err := cache.Prune(); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatalf("stale temporary file was not removed '%v'", err)
+	}
+	if _, err := os.Stat(running); err != nil {
+		t.Fatalf("running download was removed '%v'", err)
+	}
+}
@@ -3,6 +3,7 @@ package awin_go
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/csv"
 	"encoding/json"
 	"errors"
@@ -11,8 +12,10 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"reflect"
 	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 type mockRoundTripper struct {
@@ -79,7 +82,7 @@ func parseCSVToDataFeedRow(csvContent string) (*[]awin.DataFeedListRow, error) {
 func parseCSVToDataFeedEntry(csvContent string) (*[]awin.DataFeedEntry, error) {
 	reader := csv.NewReader(strings.NewReader(csvContent))
 	var entries []awin.DataFeedEntry
-	columnNamesSkipped := false
+	var columnNames []string
 	for {
 		record, err := reader.Read()
 
@@ -91,8 +94,8 @@ func parseCSVToDataFeedEntry(csvContent string) (*[]awin.DataFeedEntry, error) {
 		}
 
 		// Skip column names from csv
-		if !columnNamesSkipped {
-			columnNamesSkipped = true
+		if columnNames == nil {
+			columnNames = record
 			continue
 		}
 
@@ -185,6 +188,16 @@ func parseCSVToDataFeedEntry(csvContent string) (*[]awin.DataFeedEntry, error) {
 			BasketLink:                    record[85],
 		}
 
+		// Columns without a field end up in Extra
+		for i := 86; i < len(record); i++ {
+			if record[i] != "" {
+				if entry.Extra == nil {
+					entry.Extra = map[string]string{}
+				}
+				entry.Extra[columnNames[i]] = record[i]
+			}
+		}
+
 		entries = append(entries, entry)
 	}
 
@@ -303,7 +316,7 @@ func TestFetchDataFeed(t *testing.T) {
 	expectedRows, _ := parseCSVToDataFeedEntry(csvContent)
 	for i, expectedRow := range *expectedRows {
 		receivedRow := (*result)[i]
-		if expectedRow != receivedRow {
+		if !reflect.DeepEqual(expectedRow, receivedRow) {
 
 			eJson, _ := json.Marshal(expectedRow)
 			rJson, _ := json.Marshal(receivedRow)
@@ -370,8 +383,277 @@ func TestFetchDataFeedFromUrl(t *testing.T) {
 	expectedRows, _ := parseCSVToDataFeedEntry(csvContent)
 	for i, expectedRow := range *expectedRows {
 		receivedRow := (*result)[i]
-		if expectedRow != receivedRow {
+		if !reflect.DeepEqual(expectedRow, receivedRow) {
 			t.Fatalf("Invalid row parsed\nexpected '%v'\nreceived '%v'", expectedRow, receivedRow)
 		}
 	}
 }
+
+func TestStreamDataFeed(t *testing.T) {
+	// Read mock data from CSV
+	csvContent, err := readCSVFileContents("testdata/data_feed.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	if _, err := gz.Write([]byte(csvContent)); err != nil {
+		t.Error(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Error(err)
+	}
+
+	// Create mock response
+	response := &http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBuffer(b.Bytes())),
+	}
+
+	// Create test client to run tests on
+	awinClient := awin.NewAwinClient("apiKey", &http.Client{Transport: mockRoundTripper{response: response, requestTestFunc: func(r *http.Request) error {
+		return nil
+	}}})
+
+	reader, err := awinClient.StreamDataFeed(&awin.DataFeedOptions{
+		FeedIds:  []string{"fid1"},
+		Language: "en",
+	})
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	defer reader.Close()
+
+	// Check if streamed rows and expected rows match
+	expectedRows, _ := parseCSVToDataFeedEntry(csvContent)
+	i := 0
+	for reader.Next() {
+		if i >= len(*expectedRows) {
+			t.Fatalf("Too many rows streamed %d", i+1)
+		}
+		if expectedRow, receivedRow := (*expectedRows)[i], reader.Entry(); !reflect.DeepEqual(expectedRow, receivedRow) {
+			t.Fatalf("Invalid row streamed\nexpected '%v'\nreceived '%v'", expectedRow, receivedRow)
+		}
+		i++
+	}
+	if err := reader.Err(); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	if i != 10 {
+		t.Fatalf("Invalid amount of data rows streamed %d", i)
+	}
+}
+
+func TestStreamDataFeedFromUrlError(t *testing.T) {
+	// Create mock response
+	response := &http.Response{
+		StatusCode: 500,
+		Body:       ioutil.NopCloser(bytes.NewBufferString("internal error")),
+	}
+
+	// Create test client to run tests on
+	awinClient := awin.NewAwinClient("apiKey", &http.Client{Transport: mockRoundTripper{response: response, requestTestFunc: func(r *http.Request) error {
+		return nil
+	}}})
+
+	reader, err := awinClient.StreamDataFeedFromUrl("https://productdata.awin.com/datafeed/download/apikey/apiKey/")
+	if err == nil {
+		reader.Close()
+		t.Fatal("expected error for non 200 response")
+	}
+
+	var apiErr *awin.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, received '%v'", err)
+	}
+
+	expectedError := awin.APIError{
+		StatusCode: 500,
+		URL:        "https://productdata.awin.com/datafeed/download/apikey/REDACTED/",
+		Body:       "internal error",
+		Kind:       awin.ErrorKindServer,
+	}
+	if *apiErr != expectedError {
+		t.Fatalf("Invalid error received\nexpected '%v'\nreceived '%v'", expectedError, *apiErr)
+	}
+}
+
+func TestFetchDataFeedListError(t *testing.T) {
+	// Create mock response
+	response := &http.Response{
+		StatusCode: 401,
+		Body:       ioutil.NopCloser(bytes.NewBufferString("<html>invalid api key</html>")),
+	}
+
+	// Create test client to run tests on
+	awinClient := awin.NewAwinClient("secretApiKey", &http.Client{Transport: mockRoundTripper{response: response, requestTestFunc: func(r *http.Request) error {
+		return nil
+	}}})
+
+	_, err := awinClient.FetchDataFeedList()
+
+	var apiErr *awin.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, received '%v'", err)
+	}
+
+	if apiErr.Kind != awin.ErrorKindAuth || apiErr.StatusCode != 401 {
+		t.Fatalf("Invalid error classification '%v'", apiErr)
+	}
+
+	if strings.Contains(err.Error(), "secretApiKey") {
+		t.Fatalf("api key not redacted in '%v'", err)
+	}
+}
+
+func TestFetchDataFeedListWithContextCancelled(t *testing.T) {
+	// Read mock data from CSV
+	csvContent, err := readCSVFileContents("testdata/data_feed_list.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+
+	// Create mock response
+	response := &http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(csvContent)),
+	}
+
+	// Create test client to run tests on
+	awinClient := awin.NewAwinClient("apiKey", &http.Client{Transport: mockRoundTripper{response: response, requestTestFunc: func(r *http.Request) error {
+		return nil
+	}}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := awinClient.FetchDataFeedListWithContext(ctx); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, received '%v'", err)
+	}
+}
+
+func TestStreamDataFeedWithContextCancelled(t *testing.T) {
+	// Read mock data from CSV
+	csvContent, err := readCSVFileContents("testdata/data_feed.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	if _, err := gz.Write([]byte(csvContent)); err != nil {
+		t.Error(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Error(err)
+	}
+
+	// Create mock response, the body is read in small chunks so cancellation hits before the end
+	response := &http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(iotest.OneByteReader(bytes.NewBuffer(b.Bytes()))),
+	}
+
+	// Create test client to run tests on
+	awinClient := awin.NewAwinClient("apiKey", &http.Client{Transport: mockRoundTripper{response: response, requestTestFunc: func(r *http.Request) error {
+		return nil
+	}}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reader, err := awinClient.StreamDataFeedWithContext(ctx, &awin.DataFeedOptions{
+		FeedIds:  []string{"fid1"},
+		Language: "en",
+	})
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	defer reader.Close()
+
+	if !reader.Next() {
+		t.Fatalf("expected first row, err '%v'", reader.Err())
+	}
+
+	cancel()
+	for reader.Next() {
+	}
+
+	if reader.Err() != context.Canceled {
+		t.Fatalf("expected context.Canceled, received '%v'", reader.Err())
+	}
+}
+
+func TestFetchDataFeedWithColumns(t *testing.T) {
+	// Read mock data from CSV
+	csvContent, err := readCSVFileContents("testdata/data_feed.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	if _, err := gz.Write([]byte(csvContent)); err != nil {
+		t.Error(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Error(err)
+	}
+
+	// Create mock response
+	response := &http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBuffer(b.Bytes())),
+	}
+
+	// Create test client to run tests on
+	awinClient := awin.NewAwinClient("apiKey", &http.Client{Transport: mockRoundTripper{response: response, requestTestFunc: func(r *http.Request) error {
+		expectedUrl := "https://productdata.awin.com/datafeed/download/apikey/apiKey/language/en/fid/fid1/columns/aw_product_id,product_name,search_price/format/csv/delimiter/,/compression/gzip/adultcontent/0/"
+		if r.URL.String() != expectedUrl {
+			err := errors.New(fmt.Sprintf("invalid url found in test\nexpected '%s'\nfound '%s'", expectedUrl, r.URL.String()))
+			t.Error(err)
+			return err
+		}
+		return nil
+	}}})
+
+	result, err := awinClient.FetchDataFeed(&awin.DataFeedOptions{
+		FeedIds:  []string{"fid1"},
+		Language: "en",
+		Columns:  []awin.DataFeedColumn{awin.ColumnAwProductId, awin.ColumnProductName, awin.ColumnSearchPrice},
+	})
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	// Only the selected columns are populated, even though the response contains all of them
+	expectedRows, _ := parseCSVToDataFeedEntry(csvContent)
+	for i, expectedRow := range *expectedRows {
+		expectedRow = awin.DataFeedEntry{
+			AwProductId: expectedRow.AwProductId,
+			ProductName: expectedRow.ProductName,
+			SearchPrice: expectedRow.SearchPrice,
+		}
+		if receivedRow := (*result)[i]; !reflect.DeepEqual(expectedRow, receivedRow) {
+			t.Fatalf("Invalid row parsed\nexpected '%v'\nreceived '%v'", expectedRow, receivedRow)
+		}
+	}
+}
+
+func TestFetchDataFeedWithUnknownColumn(t *testing.T) {
+	// Create test client to run tests on
+	awinClient := awin.NewAwinClient("apiKey", &http.Client{Transport: mockRoundTripper{requestTestFunc: func(r *http.Request) error {
+		err := errors.New("no request expected for invalid columns")
+		t.Error(err)
+		return err
+	}}})
+
+	_, err := awinClient.FetchDataFeed(&awin.DataFeedOptions{
+		FeedIds:  []string{"fid1"},
+		Language: "en",
+		Columns:  []awin.DataFeedColumn{awin.ColumnAwProductId, "unknown_column"},
+	})
+	if err == nil {
+		t.Fatal("expected error for unknown column")
+	}
+}
@@ -0,0 +1,173 @@
+package awin_go
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/matthiasbruns/awin-go/awin"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// rewriteCSV returns the csv content with delimiter as column separator
+func rewriteCSV(t *testing.T, content string, delimiter rune) string {
+	records, err := csv.NewReader(strings.NewReader(content)).ReadAll()
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	var b bytes.Buffer
+	writer := csv.NewWriter(&b)
+	writer.Comma = delimiter
+	if err := writer.WriteAll(records); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	return b.String()
+}
+
+func gzipContent(t *testing.T, content string) string {
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	if _, err := gz.Write([]byte(content)); err != nil {
+		t.Error(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Error(err)
+	}
+	return b.String()
+}
+
+func zipContent(t *testing.T, content string) string {
+	var b bytes.Buffer
+	archive := zip.NewWriter(&b)
+	file, err := archive.Create("datafeed_123.csv")
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if _, err := file.Write([]byte(content)); err != nil {
+		t.Error(err)
+	}
+	if err := archive.Close(); err != nil {
+		t.Error(err)
+	}
+	return b.String()
+}
+
+func TestFetchDataFeedFromUrlDetectsCompressionAndDelimiter(t *testing.T) {
+	csvContent, err := readCSVFileContents("testdata/data_feed.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+	expectedRows, _ := parseCSVToDataFeedEntry(csvContent)
+
+	for name, body := range map[string]string{
+		"plain":      csvContent,
+		"zip":        zipContent(t, csvContent),
+		"gzip pipe":  gzipContent(t, rewriteCSV(t, csvContent, '|')),
+		"zip tab":    zipContent(t, rewriteCSV(t, csvContent, '\t')),
+		"plain pipe": rewriteCSV(t, csvContent, '|'),
+	} {
+		awinClient := awin.NewAwinClient("apiKey", &http.Client{Transport: mockRoundTripper{
+			response:        statusResponse(200, body)(),
+			requestTestFunc: func(r *http.Request) error { return nil },
+		}})
+
+		result, err := awinClient.FetchDataFeedFromUrl("https://productdata.awin.com/datafeed/download/apikey/apiKey/language/en/fid/fid1/")
+		if err != nil {
+			t.Fatalf("%s: err is not null '%v'", name, err)
+		}
+
+		if len(*result) != len(*expectedRows) {
+			t.Fatalf("%s: Invalid amount of data rows received %d", name, len(*result))
+		}
+		for i, expectedRow := range *expectedRows {
+			if !reflect.DeepEqual(expectedRow, (*result)[i]) {
+				t.Fatalf("%s: Invalid row parsed\nexpected '%v'\nreceived '%v'", name, expectedRow, (*result)[i])
+			}
+		}
+	}
+}
+
+func TestFetchDataFeedDelimiterAndCompression(t *testing.T) {
+	csvContent, err := readCSVFileContents("testdata/data_feed.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+
+	awinClient := awin.NewAwinClient("apiKey", &http.Client{Transport: mockRoundTripper{
+		response: statusResponse(200, rewriteCSV(t, csvContent, '\t'))(),
+		requestTestFunc: func(r *http.Request) error {
+			url := r.URL.String()
+			if !strings.Contains(url, "/delimiter/%09/adultcontent/0/") {
+				err := errors.New(fmt.Sprintf("invalid delimiter or compression in url '%s'", url))
+				t.Error(err)
+				return err
+			}
+			return nil
+		},
+	}})
+
+	result, err := awinClient.FetchDataFeed(&awin.DataFeedOptions{
+		FeedIds:     []string{"fid1"},
+		Language:    "en",
+		Delimiter:   awin.DelimiterTab,
+		Compression: awin.CompressionNone,
+	})
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	if len(*result) != 10 {
+		t.Fatalf("Invalid amount of data rows received %d", len(*result))
+	}
+}
+
+func TestFetchDataFeedZipCompressionUrl(t *testing.T) {
+	awinClient := awin.NewAwinClient("apiKey", &http.Client{Transport: mockRoundTripper{
+		response: statusResponse(200, zipContent(t, "aw_product_id,product_name\n1,Shoe\n"))(),
+		requestTestFunc: func(r *http.Request) error {
+			url := r.URL.String()
+			if !strings.Contains(url, "/delimiter/%7C/compression/zip/") {
+				err := errors.New(fmt.Sprintf("invalid delimiter or compression in url '%s'", url))
+				t.Error(err)
+				return err
+			}
+			return nil
+		},
+	}})
+
+	// Awin returns the requested delimiter, the mocked comma separated body is read as a single column
+	result, err := awinClient.FetchDataFeed(&awin.DataFeedOptions{
+		FeedIds:     []string{"fid1"},
+		Language:    "en",
+		Delimiter:   awin.DelimiterPipe,
+		Compression: awin.CompressionZip,
+	})
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if len(*result) != 1 {
+		t.Fatalf("Invalid amount of data rows received %d", len(*result))
+	}
+}
+
+func TestFetchDataFeedInvalidDelimiterAndCompression(t *testing.T) {
+	awinClient := awin.NewAwinClient("apiKey", &http.Client{Transport: mockRoundTripper{
+		requestTestFunc: func(r *http.Request) error {
+			t.Error("no request expected")
+			return nil
+		},
+	}})
+
+	if _, err := awinClient.FetchDataFeed(&awin.DataFeedOptions{FeedIds: []string{"fid1"}, Delimiter: ';'}); err == nil {
+		t.Fatal("expected error for unsupported delimiter")
+	}
+	if _, err := awinClient.FetchDataFeed(&awin.DataFeedOptions{FeedIds: []string{"fid1"}, Compression: "bzip2"}); err == nil {
+		t.Fatal("expected error for unknown compression")
+	}
+}
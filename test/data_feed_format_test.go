@@ -0,0 +1,172 @@
+package awin_go
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"github.com/matthiasbruns/awin-go/awin"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func readDataFeed(t *testing.T, content string, format awin.DataFeedFormat) []awin.DataFeedEntry {
+	var reader *awin.DataFeedReader
+	var err error
+	if format == "" {
+		reader, err = awin.NewDataFeedReader(strings.NewReader(content))
+	} else {
+		reader, err = awin.NewDataFeedReaderWithFormat(strings.NewReader(content), format)
+	}
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	var entries []awin.DataFeedEntry
+	for reader.Next() {
+		entries = append(entries, reader.Entry())
+	}
+	if err := reader.Err(); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	return entries
+}
+
+func expectedFormatEntries(t *testing.T) []awin.DataFeedEntry {
+	csvContent, err := readCSVFileContents("testdata/data_feed.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+
+	// The xml and json fixtures contain the first 3 entries of the csv fixture without the Fashion columns
+	expectedRows, _ := parseCSVToDataFeedEntry(csvContent)
+	entries := (*expectedRows)[:3]
+	for i := range entries {
+		entries[i].Extra = nil
+	}
+	return entries
+}
+
+func TestDataFeedReaderFlatFormats(t *testing.T) {
+	expectedRows := expectedFormatEntries(t)
+
+	for _, test := range []struct {
+		file   string
+		format awin.DataFeedFormat
+	}{
+		{"testdata/data_feed.xml", awin.FormatXml},
+		{"testdata/data_feed.xml", ""},
+		{"testdata/data_feed.json", awin.FormatJson},
+		{"testdata/data_feed.json", ""},
+	} {
+		content, err := readCSVFileContents(test.file)
+		if err != nil {
+			t.Fatalf("coult not read file '%v'", err)
+		}
+
+		entries := readDataFeed(t, content, test.format)
+		if len(entries) != len(expectedRows) {
+			t.Fatalf("Invalid amount of data rows received from %s: %d", test.file, len(entries))
+		}
+		for i, expectedRow := range expectedRows {
+			if !reflect.DeepEqual(expectedRow, entries[i]) {
+				t.Fatalf("Invalid row parsed from %s\nexpected '%v'\nreceived '%v'", test.file, expectedRow, entries[i])
+			}
+		}
+	}
+}
+
+func TestDataFeedReaderXmlTree(t *testing.T) {
+	expectedRows := expectedFormatEntries(t)
+
+	content, err := readCSVFileContents("testdata/data_feed_tree.xml")
+	if err != nil {
+		t.Fatalf("coult not read file '%v'", err)
+	}
+
+	entries := readDataFeed(t, content, awin.FormatXmlTree)
+	if len(entries) != len(expectedRows) {
+		t.Fatalf("Invalid amount of data rows received %d", len(entries))
+	}
+
+	// The tree fixture only carries a subset of the columns
+	for i, e := range expectedRows {
+		expectedRow := awin.DataFeedEntry{
+			AwDeepLink: e.AwDeepLink, ProductName: e.ProductName, AwProductId: e.AwProductId,
+			MerchantProductId: e.MerchantProductId, MerchantImageUrl: e.MerchantImageUrl, Description: e.Description,
+			MerchantCategory: e.MerchantCategory, SearchPrice: e.SearchPrice, MerchantName: e.MerchantName,
+			MerchantId: e.MerchantId, CategoryName: e.CategoryName, CategoryId: e.CategoryId, AwImageUrl: e.AwImageUrl,
+			Currency: e.Currency, DeliveryCost: e.DeliveryCost, MerchantDeepLink: e.MerchantDeepLink,
+			BrandName: e.BrandName, RrpPrice: e.RrpPrice, InStock: e.InStock, Ean: e.Ean,
+		}
+		if !reflect.DeepEqual(expectedRow, entries[i]) {
+			t.Fatalf("Invalid row parsed\nexpected '%v'\nreceived '%v'", expectedRow, entries[i])
+		}
+	}
+}
+
+func TestDataFeedReaderJsonWrapped(t *testing.T) {
+	// Metadata in front of the products may contain arrays and objects
+	content := `{"meta":{"columns":["aw_product_id"],"source":{"ids":[1,2]}},"count":2,"products":[{"aw_product_id":"1"},{"aw_product_id":"2"}]}`
+	entries := readDataFeed(t, content, awin.FormatJson)
+	if len(entries) != 2 || entries[0].AwProductId != "1" || entries[1].AwProductId != "2" {
+		t.Fatalf("Invalid entries parsed %v", entries)
+	}
+
+	for _, content := range []string{`{"meta":{"columns":["aw_product_id"]}}`, `"products"`, ``} {
+		if _, err := awin.NewDataFeedReaderWithFormat(strings.NewReader(content), awin.FormatJson); err == nil {
+			t.Fatalf("expected error for json without products '%s'", content)
+		}
+	}
+}
+
+func TestDataFeedReaderUnknownFormat(t *testing.T) {
+	if _, err := awin.NewDataFeedReaderWithFormat(strings.NewReader(""), "yaml"); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
+
+func TestFetchDataFeedJsonFormat(t *testing.T) {
+	content, err := readCSVFileContents("testdata/data_feed.json")
+	if err != nil {
+		t.Fatalf("coult not read file '%v'", err)
+	}
+
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	if _, err := gz.Write([]byte(content)); err != nil {
+		t.Error(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Error(err)
+	}
+
+	calls := 0
+	awinClient := awin.NewAwinClient("apiKey", &http.Client{Transport: mockRoundTripper{
+		response: statusResponse(200, b.String())(),
+		requestTestFunc: func(r *http.Request) error {
+			calls++
+			if !strings.Contains(r.URL.Path, "/format/json/") {
+				err := errors.New(fmt.Sprintf("invalid format in url '%s'", r.URL.String()))
+				t.Error(err)
+				return err
+			}
+			return nil
+		},
+	}})
+
+	result, err := awinClient.FetchDataFeed(&awin.DataFeedOptions{
+		FeedIds:  []string{"fid1"},
+		Language: "en",
+		Format:   awin.FormatJson,
+	})
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	if len(*result) != 3 || calls != 1 {
+		t.Fatalf("Invalid amount of data rows received %d", len(*result))
+	}
+}
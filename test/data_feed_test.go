@@ -0,0 +1,109 @@
+package awin_go
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/matthiasbruns/awin-go/awin"
+	"testing"
+	"time"
+)
+
+func TestParseMembershipStatus(t *testing.T) {
+	tests := map[string]awin.MembershipStatus{
+		"active":     awin.MembershipStatusActive,
+		"Active":     awin.MembershipStatusActive,
+		"Not Joined": awin.MembershipStatusNotJoined,
+		"not_joined": awin.MembershipStatusNotJoined,
+		"pending":    awin.MembershipStatusPending,
+		"suspended":  awin.MembershipStatusSuspended,
+	}
+
+	for input, expected := range tests {
+		status, err := awin.ParseMembershipStatus(input)
+		if err != nil {
+			t.Fatalf("err is not null for '%s': %v", input, err)
+		}
+		if status != expected {
+			t.Fatalf("invalid status parsed from '%s'\nexpected '%v'\nreceived '%v'", input, expected, status)
+		}
+	}
+
+	if status, err := awin.ParseMembershipStatus("member"); err == nil || status != awin.MembershipStatusUnknown {
+		t.Fatalf("expected unknown status and error, received '%v' '%v'", status, err)
+	}
+}
+
+func TestMembershipStatusJson(t *testing.T) {
+	j, err := json.Marshal(awin.MembershipStatusNotJoined)
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if string(j) != `"not joined"` {
+		t.Fatalf("invalid json '%s'", j)
+	}
+
+	var status awin.MembershipStatus
+	if err := json.Unmarshal(j, &status); err != nil || status != awin.MembershipStatusNotJoined {
+		t.Fatalf("invalid status unmarshalled '%v' '%v'", status, err)
+	}
+}
+
+func TestDataFeedListRowToDataFeed(t *testing.T) {
+	csvContent, err := readCSVFileContents("testdata/data_feed_list.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+
+	rows, _ := parseCSVToDataFeedRow(csvContent)
+	feed, err := (*rows)[1].ToDataFeed()
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	if !feed.IsJoined() {
+		t.Fatalf("expected joined feed, received '%v'", feed.MembershipStatus)
+	}
+	if !feed.LastImported.Equal(time.Date(2021, 8, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("invalid last imported parsed '%v'", feed.LastImported)
+	}
+	if !feed.LastChecked.Equal(time.Date(2021, 5, 20, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("invalid last checked parsed '%v'", feed.LastChecked)
+	}
+	if feed.NoOfProducts != 2 {
+		t.Fatalf("invalid product count parsed %d", feed.NoOfProducts)
+	}
+}
+
+func TestDataFeedListRowToDataFeedFieldErrors(t *testing.T) {
+	row := awin.DataFeedListRow{
+		MembershipStatus: "Not Joined",
+		LastImported:     "yesterday",
+		LastChecked:      "2021-11-03 04:15:11",
+		NoOfProducts:     "1,234",
+	}
+
+	feed, err := row.ToDataFeed()
+
+	var fieldErrors awin.FieldErrors
+	if !errors.As(err, &fieldErrors) || len(fieldErrors) != 1 || fieldErrors[0].Column != "Last Imported" {
+		t.Fatalf("expected one field error, received '%v'", err)
+	}
+	if feed.MembershipStatus != awin.MembershipStatusNotJoined || feed.NoOfProducts != 1234 || feed.LastChecked.IsZero() {
+		t.Fatalf("invalid partial feed parsed '%v'", feed)
+	}
+}
+
+func TestDataFeedEntryValue(t *testing.T) {
+	entry := awin.DataFeedEntry{AwProductId: "1", SearchPrice: "9.99", Extra: map[string]string{"Fashion:size": "L"}}
+	for column, expected := range map[awin.DataFeedColumn]string{
+		awin.ColumnAwProductId:              "1",
+		awin.ColumnSearchPrice:              "9.99",
+		awin.ColumnProductName:              "",
+		awin.DataFeedColumn("Fashion:size"): "L",
+		awin.DataFeedColumn("Fashion:fit"):  "",
+	} {
+		if value := entry.Value(column); value != expected {
+			t.Fatalf("Invalid value of %s\nexpected '%s'\nreceived '%s'", column, expected, value)
+		}
+	}
+}
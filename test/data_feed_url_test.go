@@ -0,0 +1,142 @@
+package awin_go
+
+import (
+	"github.com/matthiasbruns/awin-go/awin"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDataFeedURL(t *testing.T) {
+	feedUrl, err := awin.ParseDataFeedURL("https://productdata.awin.com/datafeed/download/apikey/secret/language/de/fid/123,456/cid/97,98/columns/aw_deep_link,product_name,aw_product_id/format/xml/delimiter/%7C/compression/zip/adultcontent/1/")
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	expected := awin.DataFeedURL{
+		BaseUrl:          "https://productdata.awin.com",
+		ApiKey:           "secret",
+		Language:         "de",
+		FeedIds:          []string{"123", "456"},
+		Columns:          []awin.DataFeedColumn{awin.ColumnAwDeepLink, awin.ColumnProductName, awin.ColumnAwProductId},
+		Format:           awin.FormatXml,
+		Delimiter:        awin.DelimiterPipe,
+		Compression:      awin.CompressionZip,
+		ShowAdultContent: true,
+		ExtraParams:      map[string]string{"cid": "97,98"},
+	}
+	if !reflect.DeepEqual(*feedUrl, expected) {
+		t.Fatalf("Invalid url parsed\nexpected '%v'\nreceived '%v'", expected, *feedUrl)
+	}
+
+	rendered := "https://productdata.awin.com/datafeed/download/apikey/secret/language/de/fid/123,456/columns/aw_deep_link,product_name,aw_product_id/format/xml/delimiter/%7C/compression/zip/cid/97,98/adultcontent/1/"
+	if feedUrl.String() != rendered {
+		t.Fatalf("Invalid url rendered\nexpected '%s'\nreceived '%s'", rendered, feedUrl.String())
+	}
+}
+
+func TestParseDataFeedURLDefaults(t *testing.T) {
+	feedUrl, err := awin.ParseDataFeedURL("http://localhost:8080/proxy/datafeed/download/apikey/secret/fid/1/columns/" + strings.Join(columnNames(awin.DataFeedColumns()), ",") + "/delimiter/%2C/")
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	if feedUrl.BaseUrl != "http://localhost:8080/proxy" || feedUrl.Columns != nil || feedUrl.Format != "" || feedUrl.Delimiter != awin.DelimiterComma {
+		t.Fatalf("Invalid url parsed '%v'", *feedUrl)
+	}
+}
+
+func TestParseDataFeedURLInvalid(t *testing.T) {
+	for _, rawUrl := range []string{
+		"ftp://productdata.awin.com/datafeed/download/apikey/secret/",
+		"https://productdata.awin.com/datafeed/list/apikey/secret",
+		"https://productdata.awin.com/datafeed/download/language/de/",
+		"https://productdata.awin.com/datafeed/download/apikey/secret/format/",
+		"https://productdata.awin.com/datafeed/download/apikey/secret/format/yaml/",
+		"https://productdata.awin.com/datafeed/download/apikey/secret/delimiter/%3B/",
+		"https://productdata.awin.com/datafeed/download/apikey/secret/compression/bzip2/",
+		"https://productdata.awin.com/datafeed/download/apikey/secret/adultcontent/yes/",
+	} {
+		_, err := awin.ParseDataFeedURL(rawUrl)
+		if err == nil {
+			t.Fatalf("expected error for '%s'", rawUrl)
+		}
+		if strings.Contains(err.Error(), "secret") {
+			t.Fatalf("api key not redacted in '%v'", err)
+		}
+	}
+}
+
+func TestDataFeedURLOptionsRoundTrip(t *testing.T) {
+	for _, options := range []awin.DataFeedOptions{
+		{FeedIds: []string{"1"}, Language: "en"},
+		{
+			FeedIds:          []string{"1", "2"},
+			Language:         "de",
+			ShowAdultContent: true,
+			Columns:          []awin.DataFeedColumn{awin.ColumnEan, awin.ColumnProductGtin},
+			Format:           awin.FormatJson,
+			Delimiter:        awin.DelimiterTab,
+			Compression:      awin.CompressionNone,
+		},
+		{FeedIds: []string{"a/1", "b 2"}, Columns: []awin.DataFeedColumn{awin.ColumnAwProductId, "Fashion:size"}},
+	} {
+		feedUrl := awin.NewDataFeedURL("secret", options)
+		parsed, err := awin.ParseDataFeedURL(feedUrl.String())
+		if err != nil {
+			t.Fatalf("err is not null '%v'", err)
+		}
+
+		// Rendering fills in the defaults, they come back explicitly
+		expected := feedUrl
+		if expected.Format == "" {
+			expected.Format = awin.FormatCsv
+		}
+		if expected.Delimiter == 0 {
+			expected.Delimiter = awin.DelimiterComma
+		}
+		if expected.Compression == "" {
+			expected.Compression = awin.CompressionGzip
+		}
+		if !reflect.DeepEqual(*parsed, expected) {
+			t.Fatalf("Invalid url parsed\nexpected '%v'\nreceived '%v'", expected, *parsed)
+		}
+		if parsed.String() != feedUrl.String() {
+			t.Fatalf("Invalid url rendered\nexpected '%s'\nreceived '%s'", feedUrl.String(), parsed.String())
+		}
+		if roundTrip := awin.NewDataFeedURL("secret", parsed.Options()); roundTrip.String() != feedUrl.String() {
+			t.Fatalf("Invalid options round trip '%s'", roundTrip.String())
+		}
+	}
+
+	// Every feed id and column is escaped on its own
+	feedUrl := awin.NewDataFeedURL("secret", awin.DataFeedOptions{FeedIds: []string{"a/1", "b 2"}, Columns: []awin.DataFeedColumn{"Fashion:size"}})
+	if !strings.Contains(feedUrl.String(), "/fid/a%2F1,b%202/columns/Fashion:size/") {
+		t.Fatalf("Invalid url rendered '%s'", feedUrl.String())
+	}
+}
+
+func TestFetchDataFeedFromUrlInvalid(t *testing.T) {
+	awinClient := awin.NewAwinClient("apiKey", &http.Client{Transport: mockRoundTripper{
+		requestTestFunc: func(r *http.Request) error {
+			t.Error("no request expected")
+			return nil
+		},
+	}})
+
+	if _, err := awinClient.FetchDataFeedFromUrl("https://productdata.awin.com/datafeed/list/apikey/apiKey"); err == nil {
+		t.Fatal("expected error for list url")
+	}
+	if _, err := awinClient.StreamDataFeedFromUrl("https://productdata.awin.com/datafeed/download/apikey/apiKey/format/yaml/"); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
+
+func columnNames(columns []awin.DataFeedColumn) []string {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = string(column)
+	}
+	return names
+}
@@ -0,0 +1,168 @@
+package awin_go
+
+import (
+	"errors"
+	"github.com/matthiasbruns/awin-go/awin"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// diffSnapshots returns two snapshots of the testdata: products 1 and 2 are removed, 9 and 10 added, 4 changed
+// its price and stock and 5 changed its description
+func diffSnapshots(t *testing.T) ([]awin.DataFeedEntry, []awin.DataFeedEntry) {
+	csvContent, err := readCSVFileContents("testdata/data_feed.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+	rows, _ := parseCSVToDataFeedEntry(csvContent)
+
+	old := append([]awin.DataFeedEntry{}, (*rows)[:8]...)
+	current := append([]awin.DataFeedEntry{}, (*rows)[2:]...)
+	current[1].SearchPrice = "1.99"
+	current[1].InStock = "0"
+	current[2].Description = "new description"
+	return old, current
+}
+
+func changeKeys(changes []awin.EntryChange) []string {
+	keys := make([]string, len(changes))
+	for i, change := range changes {
+		keys[i] = change.Key
+	}
+	return keys
+}
+
+func TestDiffDataFeedEntries(t *testing.T) {
+	old, current := diffSnapshots(t)
+
+	diff, err := awin.DiffDataFeedEntries(old, current, awin.DiffOptions{})
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	if keys := changeKeys(diff.Added); !reflect.DeepEqual(keys, []string{"10", "9"}) {
+		t.Fatalf("Invalid added products %v", keys)
+	}
+	if keys := changeKeys(diff.Removed); !reflect.DeepEqual(keys, []string{old[0].AwProductId, old[1].AwProductId}) {
+		t.Fatalf("Invalid removed products %v", keys)
+	}
+	if keys := changeKeys(diff.Modified); !reflect.DeepEqual(keys, []string{current[1].AwProductId, current[2].AwProductId}) {
+		t.Fatalf("Invalid modified products %v", keys)
+	}
+
+	modified := diff.Modified[0]
+	expectedFields := []awin.FieldChange{
+		{Column: awin.ColumnSearchPrice, Old: old[3].SearchPrice, New: "1.99"},
+		{Column: awin.ColumnInStock, Old: old[3].InStock, New: "0"},
+	}
+	if !reflect.DeepEqual(modified.Fields, expectedFields) {
+		t.Fatalf("Invalid field changes\nexpected '%v'\nreceived '%v'", expectedFields, modified.Fields)
+	}
+	if !modified.Changed(awin.ColumnSearchPrice) || modified.Changed(awin.ColumnDescription) {
+		t.Fatal("Invalid changed columns")
+	}
+	if !reflect.DeepEqual(*modified.Old, old[3]) || !reflect.DeepEqual(*modified.New, current[1]) || diff.Added[0].Old != nil || diff.Removed[0].New != nil {
+		t.Fatal("Invalid entries in changes")
+	}
+}
+
+func TestDiffDataFeedEntriesColumns(t *testing.T) {
+	old, current := diffSnapshots(t)
+
+	diff, err := awin.DiffDataFeedEntries(old, current, awin.DiffOptions{
+		Key:     awin.DiffKeyMerchantProduct,
+		Columns: []awin.DataFeedColumn{awin.ColumnSearchPrice, awin.ColumnStorePrice},
+	})
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	// The description change is ignored, the stock change is not listed
+	if len(diff.Modified) != 1 || len(diff.Modified[0].Fields) != 1 || diff.Modified[0].Key != awin.DiffKeyMerchantProduct(&current[1]) {
+		t.Fatalf("Invalid modified products %v", diff.Modified)
+	}
+	if len(diff.Added) != 2 || len(diff.Removed) != 2 {
+		t.Fatalf("Invalid amount of added %d or removed %d products", len(diff.Added), len(diff.Removed))
+	}
+}
+
+func TestDiffDataFeedEntriesCustomColumns(t *testing.T) {
+	old := []awin.DataFeedEntry{{AwProductId: "1", Extra: map[string]string{"Fashion:size": "XL"}}}
+	current := []awin.DataFeedEntry{{AwProductId: "1", Extra: map[string]string{"Fashion:size": "S"}}}
+
+	diff, err := awin.DiffDataFeedEntries(old, current, awin.DiffOptions{Columns: []awin.DataFeedColumn{"Fashion:size"}})
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	expected := []awin.FieldChange{{Column: "Fashion:size", Old: "XL", New: "S"}}
+	if len(diff.Modified) != 1 || !reflect.DeepEqual(diff.Modified[0].Fields, expected) {
+		t.Fatalf("Invalid modified products %v", diff.Modified)
+	}
+}
+
+func TestStreamDiffSortedDataFeeds(t *testing.T) {
+	old, current := diffSnapshots(t)
+	for _, entries := range [][]awin.DataFeedEntry{old, current} {
+		entries := entries
+		sort.Slice(entries, func(i, j int) bool { return entries[i].AwProductId < entries[j].AwProductId })
+	}
+
+	expected, err := awin.DiffDataFeedEntries(old, current, awin.DiffOptions{})
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	var changes []awin.EntryChange
+	err = awin.StreamDiffSortedDataFeeds(awin.NewEntrySliceIterator(old), awin.NewEntrySliceIterator(current), awin.DiffOptions{}, func(change awin.EntryChange) error {
+		changes = append(changes, change)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	if len(changes) != len(expected.Added)+len(expected.Removed)+len(expected.Modified) {
+		t.Fatalf("Invalid amount of changes %d", len(changes))
+	}
+	if !sort.SliceIsSorted(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key }) {
+		t.Fatalf("Changes are not in key order %v", changeKeys(changes))
+	}
+	for _, change := range changes {
+		if change.Type == awin.ChangeModified && change.Key == expected.Modified[0].Key && !reflect.DeepEqual(change.Fields, expected.Modified[0].Fields) {
+			t.Fatalf("Invalid field changes '%v'", change.Fields)
+		}
+	}
+}
+
+func TestDiffDataFeedsKeyErrors(t *testing.T) {
+	old, current := diffSnapshots(t)
+	var keyErr *awin.DiffKeyError
+
+	duplicated := append(append([]awin.DataFeedEntry{}, current...), current[0])
+	if _, err := awin.DiffDataFeedEntries(old, duplicated, awin.DiffOptions{}); !errors.As(err, &keyErr) {
+		t.Fatalf("expected DiffKeyError for duplicated key, received '%v'", err)
+	}
+
+	emptyKey := append([]awin.DataFeedEntry{}, current...)
+	emptyKey[0].AwProductId = ""
+	if _, err := awin.DiffDataFeedEntries(old, emptyKey, awin.DiffOptions{}); !errors.As(err, &keyErr) {
+		t.Fatalf("expected DiffKeyError for empty key, received '%v'", err)
+	}
+
+	unsorted := []awin.DataFeedEntry{{AwProductId: "2"}, {AwProductId: "1"}}
+	err := awin.StreamDiffSortedDataFeeds(awin.NewEntrySliceIterator(nil), awin.NewEntrySliceIterator(unsorted), awin.DiffOptions{}, func(change awin.EntryChange) error {
+		return nil
+	})
+	if !errors.As(err, &keyErr) {
+		t.Fatalf("expected DiffKeyError for unsorted snapshot, received '%v'", err)
+	}
+
+	stop := errors.New("stop")
+	err = awin.StreamDiffDataFeeds(awin.NewEntrySliceIterator(old), awin.NewEntrySliceIterator(current), awin.DiffOptions{}, func(change awin.EntryChange) error {
+		return stop
+	})
+	if err != stop {
+		t.Fatalf("expected emit error, received '%v'", err)
+	}
+}
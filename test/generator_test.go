@@ -0,0 +1,145 @@
+package awin_go
+
+import (
+	"bytes"
+	"github.com/gocarina/gocsv"
+	"github.com/matthiasbruns/awin-go/awin"
+	"github.com/matthiasbruns/awin-go/awin/awintest"
+	"reflect"
+	"testing"
+)
+
+func TestGeneratorIsDeterministic(t *testing.T) {
+	options := awintest.GeneratorOptions{Seed: 42, Rows: 50, Unicode: true, Messy: true, MissingColumns: 5}
+
+	var first, second bytes.Buffer
+	if err := awintest.WriteDataFeedCsv(&first, options); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if err := awintest.WriteDataFeedCsv(&second, options); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Fatal("the same seed generated different feeds")
+	}
+
+	options.Seed = 43
+	var other bytes.Buffer
+	if err := awintest.WriteDataFeedCsv(&other, options); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if bytes.Equal(first.Bytes(), other.Bytes()) {
+		t.Fatal("different seeds generated the same feed")
+	}
+}
+
+func TestGeneratedFeedsParse(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		options := awintest.GeneratorOptions{
+			Seed:           seed,
+			Rows:           100,
+			Delimiter:      []awin.DataFeedDelimiter{awin.DelimiterComma, awin.DelimiterPipe, awin.DelimiterTab}[seed%3],
+			Unicode:        seed%2 == 0,
+			Messy:          seed%4 < 2,
+			MissingColumns: int(seed % 5),
+		}
+
+		var b bytes.Buffer
+		if err := awintest.WriteDataFeedCsv(&b, options); err != nil {
+			t.Fatalf("seed %d: err is not null '%v'", seed, err)
+		}
+
+		reader, err := awin.NewDataFeedReader(&b)
+		if err != nil {
+			t.Fatalf("seed %d: err is not null '%v'", seed, err)
+		}
+
+		// Entries without the columns left out of the csv
+		expected := awintest.GenerateEntries(awintest.GeneratorOptions{Seed: seed, Rows: options.Rows, Unicode: options.Unicode, Messy: options.Messy, Delimiter: options.Delimiter})
+		columns := map[string]bool{}
+		for _, column := range awintest.GeneratedColumns(options) {
+			columns[string(column)] = true
+		}
+		entryType := reflect.TypeOf(awin.DataFeedEntry{})
+		for i := range expected {
+			value := reflect.ValueOf(&expected[i]).Elem()
+			for index := 0; index < entryType.NumField(); index++ {
+				if field := entryType.Field(index); field.Type.Kind() == reflect.String && !columns[field.Tag.Get("csv")] {
+					value.Field(index).SetString("")
+				}
+			}
+		}
+
+		count := 0
+		for reader.Next() {
+			if count >= len(expected) {
+				t.Fatalf("seed %d: too many entries parsed", seed)
+			}
+			if !reflect.DeepEqual(expected[count], reader.Entry()) {
+				t.Fatalf("seed %d: Invalid row parsed\nexpected '%v'\nreceived '%v'", seed, expected[count], reader.Entry())
+			}
+			count++
+		}
+		if err := reader.Err(); err != nil {
+			t.Fatalf("seed %d: err is not null '%v'", seed, err)
+		}
+		if count != options.Rows {
+			t.Fatalf("seed %d: Invalid amount of data rows received %d", seed, count)
+		}
+	}
+}
+
+func TestGeneratedFeedListParses(t *testing.T) {
+	options := awintest.GeneratorOptions{Seed: 7, Rows: 30, Unicode: true, Messy: true}
+
+	var b bytes.Buffer
+	if err := awintest.WriteDataFeedListCsv(&b, options); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	var rows []awin.DataFeedListRow
+	if err := gocsv.UnmarshalBytes(b.Bytes(), &rows); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if !reflect.DeepEqual(rows, awintest.GenerateDataFeedListRows(options)) {
+		t.Fatalf("Invalid feed list parsed %v", rows)
+	}
+}
+
+func BenchmarkDataFeedReader(b *testing.B) {
+	var feed bytes.Buffer
+	if err := awintest.WriteDataFeedCsv(&feed, awintest.GeneratorOptions{Seed: 1, Rows: 10000, Unicode: true, Messy: true}); err != nil {
+		b.Fatalf("err is not null '%v'", err)
+	}
+	b.SetBytes(int64(feed.Len()))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		reader, err := awin.NewDataFeedReader(bytes.NewReader(feed.Bytes()))
+		if err != nil {
+			b.Fatalf("err is not null '%v'", err)
+		}
+		for reader.Next() {
+		}
+		if err := reader.Err(); err != nil {
+			b.Fatalf("err is not null '%v'", err)
+		}
+		_ = reader.Close()
+	}
+}
+
+func BenchmarkDataFeedListUnmarshal(b *testing.B) {
+	var list bytes.Buffer
+	if err := awintest.WriteDataFeedListCsv(&list, awintest.GeneratorOptions{Seed: 1, Rows: 5000}); err != nil {
+		b.Fatalf("err is not null '%v'", err)
+	}
+	b.SetBytes(int64(list.Len()))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var rows []awin.DataFeedListRow
+		if err := gocsv.UnmarshalBytes(list.Bytes(), &rows); err != nil {
+			b.Fatalf("err is not null '%v'", err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package awin_go
+
+import (
+	"errors"
+	"github.com/matthiasbruns/awin-go/awin"
+	"testing"
+)
+
+func TestParseGtin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected awin.Gtin
+		valid    bool
+	}{
+		{"4006381333931", "04006381333931", true},
+		{"036000291452", "00036000291452", true},
+		{"36000291452", "", false},
+		{"0000036000291452", "00036000291452", true},
+		{" 4006-3813 3393.1 ", "04006381333931", true},
+		{"96385074", "00000096385074", true},
+		{"10012345678902", "10012345678902", true},
+		{"4006381333932", "", false},
+		{"123456789012345", "", false},
+		{"00000000", "", false},
+		{"17", "", false},
+		{"1236", "", false},
+		{"1234565", "", false},
+		{"123456789", "", false},
+		{"1234567895", "", false},
+		{"4.00638E+12", "", false},
+		{"", "", false},
+	}
+
+	for _, test := range tests {
+		gtin, err := awin.ParseGtin(test.input)
+		if test.valid != (err == nil) {
+			t.Fatalf("unexpected error state for '%s': %v", test.input, err)
+		}
+		if gtin != test.expected {
+			t.Fatalf("invalid GTIN parsed from '%s'\nexpected '%s'\nreceived '%s'", test.input, test.expected, gtin)
+		}
+	}
+
+	gtin, _ := awin.ParseGtin("4006381333931")
+	if gtin.Gtin13() != "4006381333931" || gtin.Isbn13() != "" {
+		t.Fatalf("invalid forms of %s: %s %s", gtin, gtin.Gtin13(), gtin.Isbn13())
+	}
+}
+
+func TestParseIsbn(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected awin.Gtin
+		valid    bool
+	}{
+		{"0-306-40615-2", "09780306406157", true},
+		{"978-0-306-40615-7", "09780306406157", true},
+		{"080442957x", "09780804429573", true},
+		{"0306406153", "", false},
+		{"4006381333931", "", false},
+		{"03064061", "", false},
+	}
+
+	for _, test := range tests {
+		isbn, err := awin.ParseIsbn(test.input)
+		if test.valid != (err == nil) {
+			t.Fatalf("unexpected error state for '%s': %v", test.input, err)
+		}
+		if isbn != test.expected {
+			t.Fatalf("invalid ISBN parsed from '%s'\nexpected '%s'\nreceived '%s'", test.input, test.expected, isbn)
+		}
+	}
+
+	isbn, _ := awin.ParseIsbn("0-306-40615-2")
+	if isbn.Isbn13() != "9780306406157" {
+		t.Fatalf("invalid ISBN-13 %s", isbn.Isbn13())
+	}
+}
+
+func TestGtinGlobal(t *testing.T) {
+	tests := map[string]bool{
+		"4006381333931":  true,
+		"036000291452":   true,
+		"96385074":       true,
+		"9780306406157":  true,
+		"10012345678902": true,
+		"2001234567893":  false,
+		"212345678992":   false,
+		"02345673":       false,
+		"9812345678902":  false,
+	}
+
+	for input, global := range tests {
+		gtin, err := awin.ParseGtin(input)
+		if err != nil {
+			t.Fatalf("err is not null '%v'", err)
+		}
+		if gtin.Global() != global {
+			t.Fatalf("invalid global state of %s, expected %v", input, global)
+		}
+	}
+}
+
+func TestDataFeedEntryIdentifiers(t *testing.T) {
+	entry := awin.DataFeedEntry{
+		Ean:  "2001234567893",
+		Upc:  "0360 0029 1452",
+		Isbn: "0306406153",
+		Mpn:  " AB-1 ",
+	}
+
+	ids, err := entry.Identifiers()
+	expected := awin.Identifiers{
+		Gtin:   "00036000291452",
+		Column: awin.ColumnUpc,
+		Ean:    "02001234567893",
+		Upc:    "00036000291452",
+		Mpn:    "AB-1",
+	}
+	if ids != expected {
+		t.Fatalf("invalid identifiers\nexpected '%v'\nreceived '%v'", expected, ids)
+	}
+
+	var fieldErrs awin.FieldErrors
+	if !errors.As(err, &fieldErrs) || len(fieldErrs) != 1 || fieldErrs[0].Column != string(awin.ColumnIsbn) {
+		t.Fatalf("expected isbn field error, received '%v'", err)
+	}
+
+	// The product_GTIN column is preferred and entries without identifiers are no error
+	entry = awin.DataFeedEntry{ProductGtin: "9780306406157", Ean: "4006381333931"}
+	if ids, err := entry.Identifiers(); err != nil || ids.Gtin != "09780306406157" || ids.Column != awin.ColumnProductGtin {
+		t.Fatalf("invalid identifiers %v '%v'", ids, err)
+	}
+	if ids, err := (awin.DataFeedEntry{}).Identifiers(); err != nil || ids != (awin.Identifiers{}) {
+		t.Fatalf("invalid identifiers %v '%v'", ids, err)
+	}
+}
@@ -0,0 +1,117 @@
+package awin_go
+
+import (
+	"encoding/csv"
+	"errors"
+	"github.com/matthiasbruns/awin-go/awin"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// brokenFeed has valid rows at lines 2 and 5, a ragged row at line 3, a bare quote at line 4 and an extraneous
+// quote at line 6
+const brokenFeed = "aw_product_id,product_name,search_price\n" +
+	"1,Shoe,10.00\n" +
+	"2,Boot\n" +
+	"3,12\" display,30.00\n" +
+	"4,Shirt,40.00\n" +
+	"5,\"Jacket\"s,50.00\n"
+
+func TestLenientDataFeedReader(t *testing.T) {
+	reader, err := awin.NewLenientDataFeedReader(strings.NewReader(brokenFeed), 0.75)
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	var ids []string
+	for reader.Next() {
+		ids = append(ids, reader.Entry().AwProductId)
+	}
+	if err := reader.Err(); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if !reflect.DeepEqual(ids, []string{"1", "4"}) {
+		t.Fatalf("Invalid entries decoded %v", ids)
+	}
+
+	report := reader.Report()
+	if report.Rows != 2 || report.Skipped != 3 || report.ErrorRatio() != 0.6 || len(report.Errors) != 3 {
+		t.Fatalf("Invalid report %v", report)
+	}
+	for i, expected := range []struct {
+		line int
+		err  error
+	}{
+		{3, csv.ErrFieldCount},
+		{4, csv.ErrBareQuote},
+		{6, csv.ErrQuote},
+	} {
+		rowErr := report.Errors[i]
+		if rowErr.Line != expected.line || !errors.Is(rowErr.Err, expected.err) {
+			t.Fatalf("Invalid row error %d: %v", i, rowErr)
+		}
+	}
+	if !reflect.DeepEqual(report.Errors[0].Record, []string{"2", "Boot"}) {
+		t.Fatalf("Invalid record of ragged row %v", report.Errors[0].Record)
+	}
+}
+
+func TestLenientDataFeedReaderRejectsFeed(t *testing.T) {
+	reader, err := awin.NewLenientDataFeedReader(strings.NewReader(brokenFeed), 0.5)
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	count := 0
+	for reader.Next() {
+		count++
+	}
+
+	var ratioErr *awin.RowErrorRatioError
+	if !errors.As(reader.Err(), &ratioErr) || ratioErr.Report.Skipped != 3 || ratioErr.MaxErrorRatio != 0.5 {
+		t.Fatalf("expected error ratio error, received '%v'", reader.Err())
+	}
+	if count != 2 {
+		t.Fatalf("Invalid amount of data rows received %d", count)
+	}
+
+	// The strict reader stops at the first broken row
+	strict, err := awin.NewDataFeedReader(strings.NewReader(brokenFeed))
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	for strict.Next() {
+	}
+	if !errors.Is(strict.Err(), csv.ErrFieldCount) || strict.Report().Skipped != 0 {
+		t.Fatalf("expected field count error, received '%v'", strict.Err())
+	}
+}
+
+func TestFetchDataFeedWithReport(t *testing.T) {
+	for _, test := range []struct {
+		maxErrorRatio float64
+		rows          int
+		fails         bool
+	}{
+		{0.75, 2, false},
+		{0.1, 0, true},
+	} {
+		awinClient := awin.New("apiKey", awin.WithLenientDecoding(test.maxErrorRatio), awin.WithHttpClient(&http.Client{Transport: mockRoundTripper{
+			response:        statusResponse(200, gzipContent(t, brokenFeed))(),
+			requestTestFunc: func(r *http.Request) error { return nil },
+		}}))
+
+		entries, report, err := awinClient.FetchDataFeedWithReport(&awin.DataFeedOptions{FeedIds: []string{"1"}, Language: "en"})
+		if test.fails != (err != nil) {
+			t.Fatalf("%v: unexpected err '%v'", test.maxErrorRatio, err)
+		}
+		if report.Skipped != 3 {
+			t.Fatalf("%v: Invalid report %v", test.maxErrorRatio, report)
+		}
+		if !test.fails && len(*entries) != test.rows {
+			t.Fatalf("%v: Invalid amount of data rows received %d", test.maxErrorRatio, len(*entries))
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package awin_go
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/matthiasbruns/awin-go/awin"
+	"github.com/matthiasbruns/awin-go/awin/awintest"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type logRecord struct {
+	level string
+	msg   string
+	args  []interface{}
+}
+
+func (r logRecord) arg(key string) interface{} {
+	for i := 0; i+1 < len(r.args); i += 2 {
+		if r.args[i] == key {
+			return r.args[i+1]
+		}
+	}
+	return nil
+}
+
+// recordingLogger keeps all messages, safe for concurrent use
+type recordingLogger struct {
+	mutex   sync.Mutex
+	records []logRecord
+}
+
+func (l *recordingLogger) Debug(msg string, args ...interface{}) { l.record("debug", msg, args) }
+func (l *recordingLogger) Info(msg string, args ...interface{})  { l.record("info", msg, args) }
+func (l *recordingLogger) Warn(msg string, args ...interface{})  { l.record("warn", msg, args) }
+func (l *recordingLogger) Error(msg string, args ...interface{}) { l.record("error", msg, args) }
+
+func (l *recordingLogger) record(level string, msg string, args []interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.records = append(l.records, logRecord{level: level, msg: msg, args: args})
+}
+
+// find returns the first record with level and msg
+func (l *recordingLogger) find(level string, msg string) (logRecord, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for _, record := range l.records {
+		if record.level == level && record.msg == msg {
+			return record, true
+		}
+	}
+	return logRecord{}, false
+}
+
+// leaks returns the first record containing secret in its args
+func (l *recordingLogger) leaks(secret string) (logRecord, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for _, record := range l.records {
+		if strings.Contains(fmt.Sprint(record.args...), secret) {
+			return record, true
+		}
+	}
+	return logRecord{}, false
+}
+
+func TestLoggingOfFeedDownload(t *testing.T) {
+	server := awintest.NewServer("secretKey123")
+	defer server.Close()
+	server.AddFeed(awin.DataFeedListRow{FeedID: "1"}, awintest.GenerateEntries(awintest.GeneratorOptions{Seed: 1, Rows: 20}))
+	server.AddFault(awintest.Fault{StatusCode: http.StatusServiceUnavailable, Times: 1})
+
+	logger := &recordingLogger{}
+	awinClient := server.Client(awin.WithLogger(logger), awin.WithRetryPolicy(testRetryPolicy()))
+	if _, err := awinClient.FetchDataFeed(&awin.DataFeedOptions{FeedIds: []string{"1"}, Language: "en"}); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	if record, ok := logger.find("warn", "awin request failed"); !ok || record.arg("status") != http.StatusServiceUnavailable {
+		t.Fatalf("missing failed request in %v", logger.records)
+	}
+	if record, ok := logger.find("warn", "awin retry"); !ok || record.arg("attempt") != 1 {
+		t.Fatalf("missing retry in %v", logger.records)
+	}
+	if _, ok := logger.find("debug", "awin response"); !ok {
+		t.Fatalf("missing response in %v", logger.records)
+	}
+	record, ok := logger.find("info", "awin feed downloaded")
+	if !ok || record.arg("rows") != 20 || record.arg("bytes").(int64) <= 0 {
+		t.Fatalf("missing downloaded feed in %v", logger.records)
+	}
+	if record, ok := logger.leaks("secretKey123"); ok {
+		t.Fatalf("api key logged in %v", record)
+	}
+}
+
+func TestLoggingOfFailures(t *testing.T) {
+	server := awintest.NewServer("secretKey123")
+	defer server.Close()
+	server.AddFeed(awin.DataFeedListRow{FeedID: "1"}, awintest.GenerateEntries(awintest.GeneratorOptions{Seed: 1, Rows: 500}))
+	server.AddFault(awintest.Fault{TruncateAfter: 5000})
+
+	logger := &recordingLogger{}
+	awinClient := server.Client(awin.WithLogger(logger))
+	if _, err := awinClient.FetchDataFeed(&awin.DataFeedOptions{FeedIds: []string{"1"}, Language: "en"}); err == nil {
+		t.Fatal("expected error for truncated feed")
+	}
+	if record, ok := logger.find("error", "awin feed decode failed"); !ok || record.arg("rows").(int) == 0 {
+		t.Fatalf("missing decode error in %v", logger.records)
+	}
+	if _, ok := logger.find("error", "awin feed download failed"); !ok {
+		t.Fatalf("missing download error in %v", logger.records)
+	}
+
+	// Errors of the transport may contain the api key anywhere, they are redacted as well
+	failing := awin.New("secretKey123", awin.WithLogger(logger), awin.WithHttpClient(&http.Client{Transport: failingRoundTripper{}}))
+	if _, err := failing.FetchDataFeedList(); err == nil {
+		t.Fatal("expected error of the transport")
+	}
+	if _, ok := logger.find("error", "awin feed list download failed"); !ok {
+		t.Fatalf("missing feed list error in %v", logger.records)
+	}
+	if record, ok := logger.leaks("secretKey123"); ok {
+		t.Fatalf("api key logged in %v", record)
+	}
+}
+
+type failingRoundTripper struct{}
+
+func (failingRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return nil, errors.New("proxy rejected token secretKey123")
+}
+
+func TestStdLogger(t *testing.T) {
+	var b bytes.Buffer
+	logger := awin.NewStdLogger(log.New(&b, "", 0), awin.LogLevelInfo)
+
+	logger.Debug("hidden", "key", "value")
+	logger.Info("awin feed downloaded", "rows", 10, "url", "http://example.com")
+	logger.Error("odd", "key")
+
+	expected := "INFO awin feed downloaded rows=10 url=http://example.com\nERROR odd key\n"
+	if b.String() != expected {
+		t.Fatalf("Invalid log output\nexpected '%s'\nreceived '%s'", expected, b.String())
+	}
+}
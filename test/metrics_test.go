@@ -0,0 +1,211 @@
+package awin_go
+
+import (
+	"context"
+	"github.com/matthiasbruns/awin-go/awin"
+	"github.com/matthiasbruns/awin-go/awin/awintest"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type observedRequest struct {
+	endpoint   awin.Endpoint
+	statusCode int
+}
+
+// recordingMetrics keeps all measurements, safe for concurrent use
+type recordingMetrics struct {
+	mutex     sync.Mutex
+	requests  []observedRequest
+	retries   []awin.Endpoint
+	downloads []awin.DownloadMetrics
+}
+
+func (m *recordingMetrics) ObserveRequest(endpoint awin.Endpoint, statusCode int, duration time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.requests = append(m.requests, observedRequest{endpoint: endpoint, statusCode: statusCode})
+}
+
+func (m *recordingMetrics) ObserveRetry(endpoint awin.Endpoint) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.retries = append(m.retries, endpoint)
+}
+
+func (m *recordingMetrics) ObserveDownload(download awin.DownloadMetrics) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.downloads = append(m.downloads, download)
+}
+
+type spanKey struct{}
+
+// recordingSpan is a finished or running span of the recordingTracer
+type recordingSpan struct {
+	name       string
+	attributes map[string]interface{}
+	ended      bool
+	err        error
+}
+
+func (s *recordingSpan) SetAttribute(key string, value interface{}) {
+	s.attributes[key] = value
+}
+
+func (s *recordingSpan) End(err error) {
+	s.ended, s.err = true, err
+}
+
+type recordingTracer struct {
+	spans []*recordingSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, awin.Span) {
+	span := &recordingSpan{name: name, attributes: map[string]interface{}{}}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// spanCheckingTransport fails requests whose context carries no span
+type spanCheckingTransport struct{}
+
+func (spanCheckingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.Context().Value(spanKey{}) == nil {
+		return &http.Response{StatusCode: http.StatusTeapot, Body: http.NoBody, Header: http.Header{}}, nil
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestMetricsOfFeedDownloads(t *testing.T) {
+	server := awintest.NewServer("apiKey")
+	defer server.Close()
+	server.AddFeed(awin.DataFeedListRow{FeedID: "1"}, awintest.GenerateEntries(awintest.GeneratorOptions{Seed: 1, Rows: 20}))
+	server.AddFault(awintest.Fault{StatusCode: http.StatusServiceUnavailable, Times: 1})
+
+	metrics := &recordingMetrics{}
+	awinClient := server.Client(awin.WithMetrics(metrics), awin.WithRetryPolicy(testRetryPolicy()))
+
+	if _, err := awinClient.FetchDataFeedList(); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if _, err := awinClient.FetchDataFeed(&awin.DataFeedOptions{FeedIds: []string{"1"}, Language: "en"}); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	expectedRequests := []observedRequest{
+		{awin.EndpointFeedList, http.StatusOK},
+		{awin.EndpointDataFeed, http.StatusServiceUnavailable},
+		{awin.EndpointDataFeed, http.StatusOK},
+	}
+	if !reflect.DeepEqual(metrics.requests, expectedRequests) {
+		t.Fatalf("Invalid requests observed %v", metrics.requests)
+	}
+	if !reflect.DeepEqual(metrics.retries, []awin.Endpoint{awin.EndpointDataFeed}) {
+		t.Fatalf("Invalid retries observed %v", metrics.retries)
+	}
+
+	if len(metrics.downloads) != 2 {
+		t.Fatalf("Invalid downloads observed %v", metrics.downloads)
+	}
+	list, feed := metrics.downloads[0], metrics.downloads[1]
+	if list.Endpoint != awin.EndpointFeedList || list.Rows != 1 || list.Bytes <= 0 || list.Err != nil {
+		t.Fatalf("Invalid feed list download %v", list)
+	}
+	if feed.Endpoint != awin.EndpointDataFeed || feed.Rows != 20 || feed.Bytes <= 0 || feed.Err != nil || !reflect.DeepEqual(feed.FeedIds, []string{"1"}) {
+		t.Fatalf("Invalid feed download %v", feed)
+	}
+
+	// A truncated download is measured with its error
+	server.AddFault(awintest.Fault{TruncateAfter: 100, Times: 1})
+	if _, err := server.Client(awin.WithMetrics(metrics)).FetchDataFeed(&awin.DataFeedOptions{FeedIds: []string{"1"}, Language: "en"}); err == nil {
+		t.Fatal("expected error for truncated feed")
+	}
+	if failed := metrics.downloads[len(metrics.downloads)-1]; failed.Err == nil {
+		t.Fatalf("Invalid failed download %v", failed)
+	}
+}
+
+func TestTracingOfFeedDownloads(t *testing.T) {
+	server := awintest.NewServer("apiKey")
+	defer server.Close()
+	server.AddFeed(awin.DataFeedListRow{FeedID: "1"}, awintest.GenerateEntries(awintest.GeneratorOptions{Seed: 1, Rows: 20}))
+
+	tracer := &recordingTracer{}
+	awinClient := server.Client(awin.WithTracer(tracer), awin.WithHttpClient(&http.Client{Transport: spanCheckingTransport{}}))
+	options := &awin.DataFeedOptions{FeedIds: []string{"1"}, Language: "en"}
+
+	if _, err := awinClient.FetchDataFeedList(); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if _, err := awinClient.FetchDataFeed(options); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	reader, err := awinClient.StreamDataFeed(options)
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	reader.Next()
+	if stream := tracer.spans[2]; stream.ended {
+		t.Fatal("span of the stream ended before the reader")
+	}
+	_ = reader.Close()
+
+	if len(tracer.spans) != 3 {
+		t.Fatalf("Invalid amount of spans %d", len(tracer.spans))
+	}
+	for i, expected := range []struct {
+		name string
+		rows int
+	}{
+		{awin.SpanFetchDataFeedList, 1},
+		{awin.SpanFetchDataFeed, 20},
+		{awin.SpanFetchDataFeed, 1},
+	} {
+		span := tracer.spans[i]
+		if span.name != expected.name || !span.ended || span.err != nil || span.attributes["awin.rows"] != expected.rows {
+			t.Fatalf("Invalid span %d: %v", i, span)
+		}
+	}
+	if !reflect.DeepEqual(tracer.spans[1].attributes["awin.feed_ids"], []string{"1"}) {
+		t.Fatalf("Invalid feed ids of span %v", tracer.spans[1].attributes)
+	}
+}
+
+func TestMetricsStreamClosedEarly(t *testing.T) {
+	metrics := &recordingMetrics{}
+	awinClient := awin.New("apiKey", awin.WithMetrics(metrics), awin.WithHttpClient(&http.Client{Transport: mockRoundTripper{
+		response:        statusResponse(200, gzipContent(t, "aw_product_id\n1\n2\n3\n"))(),
+		requestTestFunc: func(r *http.Request) error { return nil },
+	}}))
+
+	reader, err := awinClient.StreamDataFeed(&awin.DataFeedOptions{FeedIds: []string{"1"}, Language: "en"})
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	reader.Next()
+	reader.Close()
+
+	if len(metrics.downloads) != 1 {
+		t.Fatalf("Invalid amount of downloads %d", len(metrics.downloads))
+	}
+	if download := metrics.downloads[0]; download.Complete || download.Err != nil || download.Rows != 1 {
+		t.Fatalf("Invalid download of closed stream %v", download)
+	}
+
+	awinClient = awin.New("apiKey", awin.WithMetrics(metrics), awin.WithHttpClient(&http.Client{Transport: mockRoundTripper{
+		response:        statusResponse(200, gzipContent(t, "aw_product_id\n1\n2\n3\n"))(),
+		requestTestFunc: func(r *http.Request) error { return nil },
+	}}))
+	entries, err := awinClient.FetchDataFeed(&awin.DataFeedOptions{FeedIds: []string{"1"}, Language: "en"})
+	if err != nil || len(*entries) != 3 {
+		t.Fatalf("Invalid result %v '%v'", entries, err)
+	}
+	if download := metrics.downloads[1]; !download.Complete || download.Rows != 3 {
+		t.Fatalf("Invalid complete download %v", download)
+	}
+}
@@ -0,0 +1,69 @@
+package awin_go
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"github.com/matthiasbruns/awin-go/awin"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchDataFeeds(t *testing.T) {
+	csvContent, err := readCSVFileContents("testdata/data_feed.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	if _, err := gz.Write([]byte(csvContent)); err != nil {
+		t.Error(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Error(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/fid/broken/") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if !strings.Contains(r.URL.Path, "/fid/fid1/") && !strings.Contains(r.URL.Path, "/fid/fid2/") {
+			t.Errorf("unexpected feed requested '%s'", r.URL.Path)
+		}
+		_, _ = w.Write(b.Bytes())
+	}))
+	defer server.Close()
+
+	awinClient := awin.New("apiKey", awin.WithBaseUrl(server.URL))
+
+	results := awinClient.FetchDataFeeds(&awin.DataFeedOptions{
+		FeedIds:  []string{"fid1", "broken", "fid2"},
+		Language: "en",
+	}, 2)
+
+	if len(results) != 3 {
+		t.Fatalf("Invalid amount of results %d", len(results))
+	}
+
+	for _, i := range []int{0, 2} {
+		result := results[i]
+		if result.Err != nil {
+			t.Fatalf("err is not null for feed %s '%v'", result.FeedId, result.Err)
+		}
+		if len(*result.Entries) != 10 {
+			t.Fatalf("Invalid amount of data rows received for feed %s: %d", result.FeedId, len(*result.Entries))
+		}
+		if result.Bytes != int64(b.Len()) {
+			t.Fatalf("Invalid amount of bytes for feed %s\nexpected %d\nreceived %d", result.FeedId, b.Len(), result.Bytes)
+		}
+	}
+
+	var apiErr *awin.APIError
+	if results[1].FeedId != "broken" || !errors.As(results[1].Err, &apiErr) || results[1].Entries != nil {
+		t.Fatalf("expected APIError for broken feed, received '%v'", results[1])
+	}
+}
@@ -0,0 +1,129 @@
+package awin_go
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/matthiasbruns/awin-go/awin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	csvContent, err := readCSVFileContents("testdata/data_feed.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	if _, err := gz.Write([]byte(csvContent)); err != nil {
+		t.Error(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Error(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expectedPath := "/datafeed/download/apikey/apiKey/language/de/fid/fid1/columns/aw_product_id,product_name/format/csv/delimiter/,/compression/gzip/adultcontent/0/"
+		if r.URL.Path != expectedPath {
+			t.Errorf("invalid path found in test\nexpected '%s'\nfound '%s'", expectedPath, r.URL.Path)
+		}
+		if r.UserAgent() != "importer/1.0" {
+			t.Errorf("invalid user agent '%s'", r.UserAgent())
+		}
+		_, _ = w.Write(b.Bytes())
+	}))
+	defer server.Close()
+
+	httpClient := &http.Client{}
+	awinClient := awin.New("apiKey",
+		awin.WithBaseUrl(server.URL+"/"),
+		awin.WithHttpClient(httpClient),
+		awin.WithUserAgent("importer/1.0"),
+		awin.WithTimeout(5*time.Second),
+		awin.WithDefaultDataFeedOptions(awin.DataFeedOptions{
+			FeedIds:  []string{"fid1"},
+			Language: "de",
+			Columns:  []awin.DataFeedColumn{awin.ColumnAwProductId, awin.ColumnProductName},
+		}),
+	)
+
+	result, err := awinClient.FetchDataFeed(nil)
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if len(*result) != 10 {
+		t.Fatalf("Invalid amount of data rows received %d", len(*result))
+	}
+
+	// Passed options are completed by the defaults
+	if _, err := awinClient.FetchDataFeed(&awin.DataFeedOptions{Language: "de"}); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	if httpClient.Timeout != 0 {
+		t.Fatalf("passed http client was modified, timeout %v", httpClient.Timeout)
+	}
+}
+
+func TestDefaultDataFeedOptionsMerge(t *testing.T) {
+	var paths []string
+	awinClient := awin.New("apiKey",
+		awin.WithHttpClient(&http.Client{Transport: mockRoundTripper{
+			response: statusResponse(200, "")(),
+			requestTestFunc: func(r *http.Request) error {
+				paths = append(paths, r.URL.Path)
+				return nil
+			},
+		}}),
+		awin.WithDefaultDataFeedOptions(awin.DataFeedOptions{
+			FeedIds:          []string{"fid1"},
+			Language:         "de",
+			ShowAdultContent: true,
+			Columns:          []awin.DataFeedColumn{awin.ColumnAwProductId},
+			Format:           awin.FormatJson,
+			Delimiter:        awin.DelimiterPipe,
+			Compression:      awin.CompressionZip,
+		}),
+	)
+
+	// Every default but ShowAdultContent survives a per call option that only sets one other field
+	for _, options := range []awin.DataFeedOptions{
+		{FeedIds: []string{"fid1"}},
+		{Language: "de"},
+		{Columns: []awin.DataFeedColumn{awin.ColumnAwProductId}},
+		{Format: awin.FormatJson},
+		{Delimiter: awin.DelimiterPipe},
+		{Compression: awin.CompressionZip},
+	} {
+		_, _ = awinClient.StreamDataFeed(&options)
+	}
+
+	expectedPath := "/datafeed/download/apikey/apiKey/language/de/fid/fid1/columns/aw_product_id/format/json/delimiter/|/compression/zip/adultcontent/0/"
+	if len(paths) != 6 {
+		t.Fatalf("Invalid amount of requests %d", len(paths))
+	}
+	for i, path := range paths {
+		if path != expectedPath {
+			t.Fatalf("%d: invalid path\nexpected '%s'\nfound '%s'", i, expectedPath, path)
+		}
+	}
+
+	// Set fields override the defaults
+	paths = nil
+	_, _ = awinClient.StreamDataFeed(&awin.DataFeedOptions{Language: "en", ShowAdultContent: true, Format: awin.FormatCsv, Delimiter: awin.DelimiterTab, Compression: awin.CompressionGzip})
+	expectedPath = "/datafeed/download/apikey/apiKey/language/en/fid/fid1/columns/aw_product_id/format/csv/delimiter/\t/compression/gzip/adultcontent/1/"
+	if len(paths) != 1 || paths[0] != expectedPath {
+		t.Fatalf("invalid path\nexpected '%s'\nfound '%v'", expectedPath, paths)
+	}
+
+	// Adult content of the defaults only applies to calls without options
+	paths = nil
+	_, _ = awinClient.StreamDataFeed(nil)
+	expectedPath = "/datafeed/download/apikey/apiKey/language/de/fid/fid1/columns/aw_product_id/format/json/delimiter/|/compression/zip/adultcontent/1/"
+	if len(paths) != 1 || paths[0] != expectedPath {
+		t.Fatalf("invalid path\nexpected '%s'\nfound '%v'", expectedPath, paths)
+	}
+}
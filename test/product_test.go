@@ -0,0 +1,140 @@
+package awin_go
+
+import (
+	"errors"
+	"github.com/matthiasbruns/awin-go/awin"
+	"testing"
+	"time"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		valid    bool
+	}{
+		{"12", "12", true},
+		{"12.50", "12.50", true},
+		{"12,99", "12.99", true},
+		{" -0.05 ", "-0.05", true},
+		{".5", "0.5", true},
+		{"1,234.50", "", false},
+		{"0,5", "0.5", true},
+		{"1,234", "", false},
+		{"12,", "", false},
+		{"+5", "5", true},
+		{"-+5", "", false},
+		{"--5", "", false},
+		{"12a", "", false},
+		{"", "", false},
+	}
+
+	for _, test := range tests {
+		d, err := awin.ParseDecimal(test.input)
+		if test.valid != (err == nil) {
+			t.Fatalf("unexpected error state for '%s': %v", test.input, err)
+		}
+		if test.valid && d.String() != test.expected {
+			t.Fatalf("invalid decimal parsed from '%s'\nexpected '%s'\nreceived '%s'", test.input, test.expected, d.String())
+		}
+	}
+
+	a, _ := awin.ParseDecimal("1.5")
+	b, _ := awin.ParseDecimal("1.50")
+	if a.Cmp(b) != 0 {
+		t.Fatalf("expected %v and %v to be equal", a, b)
+	}
+
+	// Aligning the scales must not overflow
+	for _, test := range []struct {
+		a, b     string
+		expected int
+	}{
+		{"12345678901", "0.000000001", 1},
+		{"-12345678901", "0.000000001", -1},
+		{"0.000000001", "922337203685477580", -1},
+		{"-1.5", "-1.2", -1},
+	} {
+		a, _ := awin.ParseDecimal(test.a)
+		b, _ := awin.ParseDecimal(test.b)
+		if cmp := a.Cmp(b); cmp != test.expected {
+			t.Fatalf("invalid comparison of %s and %s, expected %d, received %d", test.a, test.b, test.expected, cmp)
+		}
+		if cmp := b.Cmp(a); cmp != -test.expected {
+			t.Fatalf("invalid comparison of %s and %s, expected %d, received %d", test.b, test.a, -test.expected, cmp)
+		}
+	}
+}
+
+func TestDataFeedEntryToProduct(t *testing.T) {
+	entry := awin.DataFeedEntry{
+		AwProductId:   "1",
+		Currency:      "eur",
+		SearchPrice:   "19.99",
+		RrpPrice:      "24,50",
+		DeliveryCost:  "",
+		LastUpdated:   "2021-03-17 10:30:00",
+		ValidFrom:     "3/11/2021",
+		InStock:       "1",
+		IsForSale:     "yes",
+		StockQuantity: "42",
+		AverageRating: "4.5",
+	}
+
+	product, err := entry.ToProduct()
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	if product.SearchPrice.String() != "19.99 EUR" || product.RrpPrice.String() != "24.50 EUR" {
+		t.Fatalf("invalid prices parsed '%v' '%v'", product.SearchPrice, product.RrpPrice)
+	}
+	if product.DeliveryCost != (awin.Money{}) {
+		t.Fatalf("expected empty delivery cost, received '%v'", product.DeliveryCost)
+	}
+	if !product.LastUpdated.Equal(time.Date(2021, 3, 17, 10, 30, 0, 0, time.UTC)) {
+		t.Fatalf("invalid last updated parsed '%v'", product.LastUpdated)
+	}
+	if !product.ValidFrom.Equal(time.Date(2021, 3, 11, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("invalid valid from parsed '%v'", product.ValidFrom)
+	}
+	if !product.InStock || !product.IsForSale || product.PreOrder {
+		t.Fatalf("invalid flags parsed '%v'", product)
+	}
+	if product.StockQuantity != 42 || product.AverageRating != 4.5 {
+		t.Fatalf("invalid numbers parsed '%v' '%v'", product.StockQuantity, product.AverageRating)
+	}
+}
+
+func TestDataFeedEntryToProductFieldErrors(t *testing.T) {
+	entry := awin.DataFeedEntry{
+		Currency:      "EUR",
+		SearchPrice:   "free",
+		StorePrice:    "10",
+		InStock:       "maybe",
+		StockQuantity: "a lot",
+	}
+
+	product, err := entry.ToProduct()
+	if product == nil {
+		t.Fatal("expected partial product")
+	}
+	if product.StorePrice.String() != "10 EUR" {
+		t.Fatalf("invalid store price parsed '%v'", product.StorePrice)
+	}
+
+	var fieldErrors awin.FieldErrors
+	if !errors.As(err, &fieldErrors) {
+		t.Fatalf("expected FieldErrors, received '%v'", err)
+	}
+
+	expectedColumns := []awin.DataFeedColumn{awin.ColumnSearchPrice, awin.ColumnInStock, awin.ColumnStockQuantity}
+	if len(fieldErrors) != len(expectedColumns) {
+		t.Fatalf("invalid amount of field errors %d: %v", len(fieldErrors), err)
+	}
+	for i, column := range expectedColumns {
+		if fieldErrors[i].Column != string(column) {
+			t.Fatalf("invalid field error column\nexpected '%s'\nreceived '%s'", column, fieldErrors[i].Column)
+		}
+	}
+}
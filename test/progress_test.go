@@ -0,0 +1,117 @@
+package awin_go
+
+import (
+	"github.com/matthiasbruns/awin-go/awin"
+	"github.com/matthiasbruns/awin-go/awin/awintest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// progressRecorder collects all reports, safe for concurrent downloads
+type progressRecorder struct {
+	mutex   sync.Mutex
+	reports []awin.DataFeedProgress
+}
+
+func (r *progressRecorder) DataFeedProgress(progress awin.DataFeedProgress) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.reports = append(r.reports, progress)
+}
+
+func (r *progressRecorder) feed(feedId string) []awin.DataFeedProgress {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var reports []awin.DataFeedProgress
+	for _, report := range r.reports {
+		if len(report.FeedIds) == 1 && report.FeedIds[0] == feedId {
+			reports = append(reports, report)
+		}
+	}
+	return reports
+}
+
+func TestProgressOfFetchDataFeed(t *testing.T) {
+	server, _ := fakeServer(t)
+	defer server.Close()
+
+	recorder := &progressRecorder{}
+	_, err := server.Client(awin.WithProgressObserver(recorder, 0)).FetchDataFeed(&awin.DataFeedOptions{FeedIds: []string{"1"}, Language: "en"})
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	reports := recorder.feed("1")
+	// The first report after the response, one per entry and the final one
+	if len(reports) != 8 {
+		t.Fatalf("Invalid amount of reports %d: %v", len(reports), reports)
+	}
+
+	first, last := reports[0], reports[len(reports)-1]
+	if first.Rows != 0 || first.Done || first.ContentLength <= 0 {
+		t.Fatalf("Invalid first report %v", first)
+	}
+	if strings.Contains(first.Url, server.ApiKey) {
+		t.Fatalf("api key not redacted in '%s'", first.Url)
+	}
+	for i := 1; i < len(reports)-1; i++ {
+		if reports[i].Rows != i || reports[i].Done || reports[i].Elapsed < reports[i-1].Elapsed {
+			t.Fatalf("Invalid report %d: %v", i, reports[i])
+		}
+	}
+	if !last.Done || last.Err != nil || last.Rows != 6 || last.BytesRead != last.ContentLength || last.Percent() != 100 {
+		t.Fatalf("Invalid last report %v", last)
+	}
+}
+
+func TestProgressOfStreamClosedEarly(t *testing.T) {
+	server, _ := fakeServer(t)
+	defer server.Close()
+
+	recorder := &progressRecorder{}
+	reader, err := server.Client(awin.WithProgressObserver(recorder, 0)).StreamDataFeed(&awin.DataFeedOptions{FeedIds: []string{"1"}, Language: "en"})
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	reader.Next()
+	reader.Next()
+	_ = reader.Close()
+	_ = reader.Close()
+
+	reports := recorder.feed("1")
+	last := reports[len(reports)-1]
+	if len(reports) != 4 || !last.Done || last.Err != nil || last.Rows != 2 {
+		t.Fatalf("Invalid reports %v", reports)
+	}
+}
+
+func TestProgressOfFailedAndParallelDownloads(t *testing.T) {
+	server := awintest.NewServer("apiKey")
+	defer server.Close()
+	for _, feedId := range []string{"1", "2", "3"} {
+		server.AddFeed(awin.DataFeedListRow{FeedID: feedId}, awintest.GenerateEntries(awintest.GeneratorOptions{Seed: 1, Rows: 500}))
+	}
+	server.AddFault(awintest.Fault{FeedId: "2", TruncateAfter: 5000})
+
+	recorder := &progressRecorder{}
+	// A long interval only leaves the first and the final report
+	awinClient := server.Client(awin.WithProgressObserver(recorder, time.Hour))
+	results := awinClient.FetchDataFeeds(&awin.DataFeedOptions{FeedIds: []string{"1", "2", "3"}, Language: "en"}, 3)
+
+	for _, result := range results {
+		reports := recorder.feed(result.FeedId)
+		if len(reports) != 2 {
+			t.Fatalf("Invalid reports of feed %s: %v", result.FeedId, reports)
+		}
+		last := reports[1]
+		if !last.Done || last.Err != result.Err || (result.Err == nil && last.Rows != 500) {
+			t.Fatalf("Invalid last report of feed %s: %v", result.FeedId, last)
+		}
+	}
+	if results[1].Err == nil {
+		t.Fatal("expected error for truncated feed")
+	}
+}
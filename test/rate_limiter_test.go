@@ -0,0 +1,117 @@
+package awin_go
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"github.com/matthiasbruns/awin-go/awin"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterRequestsPerInterval(t *testing.T) {
+	limiter := awin.NewRateLimiter(2, 50*time.Millisecond, 0)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		release, err := limiter.Acquire(context.Background())
+		if err != nil {
+			t.Fatalf("err is not null '%v'", err)
+		}
+		release()
+	}
+
+	// Requests 3 and 4 wait for the first interval, request 5 for the second one
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Fatalf("rate limit not applied, 5 requests took %v", elapsed)
+	}
+}
+
+func TestRateLimiterCancelledWaitGivesBackSlot(t *testing.T) {
+	limiter := awin.NewRateLimiter(1, 500*time.Millisecond, 0)
+
+	start := time.Now()
+	release, err := limiter.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	release()
+
+	// The cancelled request would otherwise delay the next one by another interval
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded, received '%v'", err)
+	}
+
+	release, err = limiter.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	release()
+	if elapsed := time.Since(start); elapsed < 500*time.Millisecond || elapsed >= 900*time.Millisecond {
+		t.Fatalf("cancelled reservation was not given back, next request took %v", elapsed)
+	}
+}
+
+func TestRateLimiterMaxInFlight(t *testing.T) {
+	limiter := awin.NewRateLimiter(0, 0, 1)
+
+	release, err := limiter.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded, received '%v'", err)
+	}
+
+	release()
+	release, err = limiter.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	release()
+}
+
+func TestClientLimiterHoldsSlotUntilStreamClosed(t *testing.T) {
+	csvContent, err := readCSVFileContents("testdata/data_feed.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	if _, err := gz.Write([]byte(csvContent)); err != nil {
+		t.Error(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Error(err)
+	}
+
+	calls := 0
+	awinClient := awin.NewAwinClient("apiKey", &http.Client{Transport: sequenceRoundTripper{calls: &calls, responses: []func() *http.Response{
+		statusResponse(200, b.String()),
+	}}})
+	awinClient.SetLimiter(awin.NewRateLimiter(0, 0, 1))
+
+	reader, err := awinClient.StreamDataFeed(&awin.DataFeedOptions{FeedIds: []string{"fid1"}, Language: "en"})
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	// The open stream occupies the only slot
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := awinClient.StreamDataFeedWithContext(ctx, &awin.DataFeedOptions{FeedIds: []string{"fid2"}, Language: "en"}); err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded, received '%v'", err)
+	}
+
+	reader.Close()
+	if _, err := awinClient.FetchDataFeed(&awin.DataFeedOptions{FeedIds: []string{"fid2"}, Language: "en"}); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+}
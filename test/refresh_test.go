@@ -0,0 +1,200 @@
+package awin_go
+
+import (
+	"github.com/matthiasbruns/awin-go/awin"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const refreshFeedListHeader = "Advertiser ID,Advertiser Name,Primary Region,Membership Status,Feed ID,Feed Name,Language,Vertical,Last Imported,Last Checked,No of products,URL\n"
+
+func TestRefreshDataFeeds(t *testing.T) {
+	csvContent, err := readCSVFileContents("testdata/data_feed.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+	feedContent := gzipContent(t, csvContent)
+
+	var mutex sync.Mutex
+	feedList := refreshFeedListHeader +
+		"1,Merchant 1,DE,active,1,Feed 1,de,Shoes,8/5/2021,8/6/2021,10,\n" +
+		"2,Merchant 2,DE,active,2,Feed 2,de,Shoes,8/5/2021,8/6/2021,10,\n" +
+		"3,Merchant 3,DE,Not Joined,3,Feed 3,de,Shoes,8/5/2021,8/6/2021,10,\n"
+	downloads := map[string]int{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if strings.Contains(r.URL.Path, "/datafeed/list/") {
+			_, _ = w.Write([]byte(feedList))
+			return
+		}
+
+		feedId := strings.Split(strings.SplitAfter(r.URL.Path, "/fid/")[1], "/")[0]
+		downloads[feedId]++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(feedContent))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "awin-refresh")
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	defer os.RemoveAll(dir)
+	statePath := filepath.Join(dir, "state.json")
+
+	awinClient := awin.New("apiKey", awin.WithBaseUrl(server.URL))
+	refresh := func() []awin.RefreshResult {
+		state, err := awin.LoadRefreshState(statePath)
+		if err != nil {
+			t.Fatalf("err is not null '%v'", err)
+		}
+		results, err := awinClient.RefreshDataFeeds(state, &awin.DataFeedOptions{Language: "de"}, 2)
+		if err != nil {
+			t.Fatalf("err is not null '%v'", err)
+		}
+		if err := state.Save(statePath); err != nil {
+			t.Fatalf("err is not null '%v'", err)
+		}
+		return results
+	}
+
+	// First run fetches all joined feeds
+	results := refresh()
+	if len(results) != 2 {
+		t.Fatalf("Invalid amount of results %d", len(results))
+	}
+	for _, result := range results {
+		if result.Status != awin.RefreshFetched || result.Err != nil || len(*result.Entries) != 10 {
+			t.Fatalf("Invalid result for feed %s: %v '%s' '%v'", result.FeedId, result.Status, result.Reason, result.Err)
+		}
+	}
+
+	// Second run skips both feeds without downloading them
+	for _, result := range refresh() {
+		if result.Status != awin.RefreshSkippedUnchanged || result.Entries != nil {
+			t.Fatalf("Invalid result for feed %s: %v '%s'", result.FeedId, result.Status, result.Reason)
+		}
+	}
+
+	// Feed 2 has been imported again, the download is answered with 304
+	mutex.Lock()
+	feedList = strings.Replace(feedList, "2,Feed 2,de,Shoes,8/5/2021", "2,Feed 2,de,Shoes,8/7/2021", 1)
+	mutex.Unlock()
+
+	results = refresh()
+	if results[0].Status != awin.RefreshSkippedUnchanged || results[1].Status != awin.RefreshSkippedNotModified || !results[1].Status.Skipped() {
+		t.Fatalf("Invalid results %v %v", results[0].Status, results[1].Status)
+	}
+
+	if downloads["1"] != 1 || downloads["2"] != 2 || downloads["3"] != 0 {
+		t.Fatalf("Invalid downloads %v", downloads)
+	}
+
+	// The not modified feed is unchanged in the next run
+	if results = refresh(); results[1].Status != awin.RefreshSkippedUnchanged {
+		t.Fatalf("Invalid result for feed 2: %v '%s'", results[1].Status, results[1].Reason)
+	}
+}
+
+func TestRefreshDataFeedsReportsNotJoined(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Path, "/datafeed/list/") {
+			t.Errorf("unexpected download '%s'", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(refreshFeedListHeader + "3,Merchant 3,DE,Not Joined,3,Feed 3,de,Shoes,8/5/2021,8/6/2021,10,\n"))
+	}))
+	defer server.Close()
+
+	awinClient := awin.New("apiKey", awin.WithBaseUrl(server.URL))
+	results, err := awinClient.RefreshDataFeeds(awin.NewRefreshState(), &awin.DataFeedOptions{FeedIds: []string{"3"}}, 1)
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	if len(results) != 1 || results[0].Status != awin.RefreshSkippedNotJoined || results[0].Reason != "membership status is not joined" {
+		t.Fatalf("Invalid results %v", results)
+	}
+}
+
+func TestRefreshDataFeedsWithCache(t *testing.T) {
+	csvContent, err := readCSVFileContents("testdata/data_feed.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+	feedContent := gzipContent(t, csvContent)
+
+	downloads := 0
+	feedList := refreshFeedListHeader + "1,Merchant 1,DE,active,1,Feed 1,de,Shoes,8/5/2021,8/6/2021,10,\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/datafeed/list/") {
+			_, _ = w.Write([]byte(feedList))
+			return
+		}
+		downloads++
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(feedContent))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "awin-refresh-cache")
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	defer os.RemoveAll(dir)
+	cache, err := awin.NewDiskCache(dir, time.Hour, 0)
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	awinClient := awin.New("apiKey", awin.WithBaseUrl(server.URL), awin.WithCache(cache))
+	options := &awin.DataFeedOptions{FeedIds: []string{"1"}, Language: "de"}
+
+	// A cached body of the same feed carries no ETag and the cached feed list outdated timestamps, the refresh
+	// downloads both again
+	if _, err := awinClient.FetchDataFeed(options); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if _, err := awinClient.FetchDataFeedList(); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	state := awin.NewRefreshState()
+	results, err := awinClient.RefreshDataFeeds(state, options, 1)
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if results[0].Status != awin.RefreshFetched || state.Feeds["1"].ETag != `"v1"` || downloads != 2 {
+		t.Fatalf("Invalid result %v '%s', state %v, %d downloads", results[0].Status, results[0].Reason, state.Feeds["1"], downloads)
+	}
+
+	// The feed list is not served from the cache either, the new import is noticed
+	feedList = strings.Replace(feedList, "8/5/2021", "8/7/2021", 1)
+	results, err = awinClient.RefreshDataFeeds(state, options, 1)
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if results[0].Status != awin.RefreshFetched || downloads != 3 {
+		t.Fatalf("Invalid result %v '%s', %d downloads", results[0].Status, results[0].Reason, downloads)
+	}
+
+	// A nil state fetches every feed
+	results, err = awinClient.RefreshDataFeeds(nil, options, 1)
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if len(results) != 1 || results[0].Status != awin.RefreshFetched || len(*results[0].Entries) != 10 {
+		t.Fatalf("Invalid results %v", results)
+	}
+}
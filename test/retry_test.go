@@ -0,0 +1,207 @@
+package awin_go
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"github.com/matthiasbruns/awin-go/awin"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// sequenceRoundTripper answers each request with the next response factory, the last one is repeated
+type sequenceRoundTripper struct {
+	responses []func() *http.Response
+	calls     *int
+}
+
+func (s sequenceRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	i := *s.calls
+	if i >= len(s.responses) {
+		i = len(s.responses) - 1
+	}
+	*s.calls++
+	return s.responses[i](), nil
+}
+
+func statusResponse(statusCode int, body string) func() *http.Response {
+	return func() *http.Response {
+		return &http.Response{
+			StatusCode: statusCode,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+		}
+	}
+}
+
+func testRetryPolicy() awin.RetryPolicy {
+	return awin.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		Multiplier:     2,
+		Jitter:         0.5,
+	}
+}
+
+func TestFetchDataFeedListRetry(t *testing.T) {
+	csvContent, err := readCSVFileContents("testdata/data_feed_list.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+
+	calls := 0
+	awinClient := awin.NewAwinClient("apiKey", &http.Client{Transport: sequenceRoundTripper{calls: &calls, responses: []func() *http.Response{
+		statusResponse(503, "unavailable"),
+		statusResponse(502, "bad gateway"),
+		statusResponse(200, csvContent),
+	}}})
+	awinClient.SetRetryPolicy(testRetryPolicy())
+
+	result, err := awinClient.FetchDataFeedList()
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	if len(*result) != 10 || calls != 3 {
+		t.Fatalf("Invalid result after retries, %d rows in %d calls", len(*result), calls)
+	}
+}
+
+func TestFetchDataFeedListRetryExhausted(t *testing.T) {
+	calls := 0
+	awinClient := awin.NewAwinClient("apiKey", &http.Client{Transport: sequenceRoundTripper{calls: &calls, responses: []func() *http.Response{
+		statusResponse(500, "internal error"),
+	}}})
+	awinClient.SetRetryPolicy(testRetryPolicy())
+
+	_, err := awinClient.FetchDataFeedList()
+
+	var apiErr *awin.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 500 {
+		t.Fatalf("expected APIError with status 500, received '%v'", err)
+	}
+	if calls != 3 {
+		t.Fatalf("Invalid amount of calls %d", calls)
+	}
+}
+
+func TestFetchDataFeedListNoRetryForNotFound(t *testing.T) {
+	calls := 0
+	awinClient := awin.NewAwinClient("apiKey", &http.Client{Transport: sequenceRoundTripper{calls: &calls, responses: []func() *http.Response{
+		statusResponse(404, "not found"),
+	}}})
+	awinClient.SetRetryPolicy(testRetryPolicy())
+
+	if _, err := awinClient.FetchDataFeedList(); err == nil {
+		t.Fatal("expected error for not found")
+	}
+	if calls != 1 {
+		t.Fatalf("Invalid amount of calls %d", calls)
+	}
+}
+
+func TestFetchDataFeedRetryTruncatedDownload(t *testing.T) {
+	csvContent, err := readCSVFileContents("testdata/data_feed.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	if _, err := gz.Write([]byte(csvContent)); err != nil {
+		t.Error(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Error(err)
+	}
+	gzipContent := b.Bytes()
+
+	calls := 0
+	awinClient := awin.NewAwinClient("apiKey", &http.Client{Transport: sequenceRoundTripper{calls: &calls, responses: []func() *http.Response{
+		statusResponse(200, string(gzipContent[:len(gzipContent)/2])),
+		statusResponse(200, string(gzipContent)),
+	}}})
+	awinClient.SetRetryPolicy(testRetryPolicy())
+
+	result, err := awinClient.FetchDataFeed(&awin.DataFeedOptions{
+		FeedIds:  []string{"fid1"},
+		Language: "en",
+	})
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	if len(*result) != 10 || calls != 2 {
+		t.Fatalf("Invalid result after retries, %d rows in %d calls", len(*result), calls)
+	}
+}
+
+func TestAPIErrorRetryAfter(t *testing.T) {
+	calls := 0
+	awinClient := awin.NewAwinClient("apiKey", &http.Client{Transport: sequenceRoundTripper{calls: &calls, responses: []func() *http.Response{
+		func() *http.Response {
+			response := statusResponse(429, "slow down")()
+			response.Header.Set("Retry-After", "120")
+			return response
+		},
+	}}})
+
+	_, err := awinClient.FetchDataFeedList()
+
+	var apiErr *awin.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, received '%v'", err)
+	}
+	if apiErr.Kind != awin.ErrorKindRateLimited || apiErr.RetryAfter != 120*time.Second {
+		t.Fatalf("Invalid rate limit error '%v'", apiErr)
+	}
+}
+
+func TestRetryAfterLongerThanPolicy(t *testing.T) {
+	retryAfter := func(value string) func() *http.Response {
+		return func() *http.Response {
+			response := statusResponse(429, "slow down")()
+			response.Header.Set("Retry-After", value)
+			return response
+		}
+	}
+
+	// A day is not waited for, the rate limit error is returned at once
+	calls := 0
+	awinClient := awin.NewAwinClient("apiKey", &http.Client{Transport: sequenceRoundTripper{calls: &calls, responses: []func() *http.Response{
+		retryAfter("86400"),
+	}}})
+	awinClient.SetRetryPolicy(testRetryPolicy())
+
+	start := time.Now()
+	_, err := awinClient.FetchDataFeedList()
+	var apiErr *awin.APIError
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter != 24*time.Hour || calls != 1 || time.Since(start) > time.Second {
+		t.Fatalf("expected rate limit error without retry, received '%v' after %d calls", err, calls)
+	}
+
+	// Shorter waits within MaxRetryAfter are honored
+	csvContent, err := readCSVFileContents("testdata/data_feed_list.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+	calls = 0
+	awinClient = awin.NewAwinClient("apiKey", &http.Client{Transport: sequenceRoundTripper{calls: &calls, responses: []func() *http.Response{
+		retryAfter("1"),
+		statusResponse(200, csvContent),
+	}}})
+	policy := testRetryPolicy()
+	policy.MaxRetryAfter = time.Second
+	awinClient.SetRetryPolicy(policy)
+
+	start = time.Now()
+	if _, err := awinClient.FetchDataFeedList(); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if calls != 2 || time.Since(start) < time.Second {
+		t.Fatalf("Retry-After not honored, %d calls after %v", calls, time.Since(start))
+	}
+}
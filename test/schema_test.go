@@ -0,0 +1,174 @@
+package awin_go
+
+import (
+	"errors"
+	"github.com/matthiasbruns/awin-go/awin"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// renamedFeed has product_name renamed to Product_Name and a new colour_code column
+const renamedFeed = "aw_product_id,Product_Name,search_price,colour_code\n" +
+	"1,Shoe,10.00,\n" +
+	"2,Boot,20.00,#000000\n"
+
+func TestDataFeedReaderSchemaDrift(t *testing.T) {
+	csvContent, err := readCSVFileContents("testdata/data_feed.csv")
+	if err != nil {
+		t.Fatalf("coult not parse csv file '%v'", err)
+	}
+
+	reader, err := awin.NewDataFeedReader(strings.NewReader(csvContent))
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	expectedUnknown := []string{"Fashion:size", "Fashion:material", "Fashion:pattern", "Fashion:swatch", "Fashion:suitable_for", "Fashion:category"}
+	if drift := reader.SchemaDrift(); !reflect.DeepEqual(drift.Unknown, expectedUnknown) || len(drift.Missing) != 0 {
+		t.Fatalf("Invalid schema drift %v", drift)
+	}
+
+	var entries []awin.DataFeedEntry
+	for reader.Next() {
+		entries = append(entries, reader.Entry())
+	}
+	if err := reader.Err(); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	expectedExtra := map[string]string{
+		"Fashion:size":         "L",
+		"Fashion:material":     "Vinyl",
+		"Fashion:pattern":      "clear-thinking",
+		"Fashion:swatch":       "Purple",
+		"Fashion:suitable_for": "false",
+		"Fashion:category":     "Books",
+	}
+	if !reflect.DeepEqual(entries[0].Extra, expectedExtra) {
+		t.Fatalf("Invalid extra values %v", entries[0].Extra)
+	}
+	// Empty values are left out
+	if entries[1].Extra != nil {
+		t.Fatalf("Invalid extra values %v", entries[1].Extra)
+	}
+
+	if report := reader.Report(); report.Rows != len(entries) || !reflect.DeepEqual(report.Drift, reader.SchemaDrift()) {
+		t.Fatalf("Invalid report %v", report)
+	}
+}
+
+func TestDataFeedReaderRenamedColumn(t *testing.T) {
+	reader, err := awin.NewDataFeedReader(strings.NewReader(renamedFeed))
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	drift := reader.SchemaDrift()
+	if !reflect.DeepEqual(drift.Unknown, []string{"Product_Name", "colour_code"}) || len(drift.Missing) != len(awin.DataFeedColumns())-2 || drift.Missing[0] != awin.ColumnAwDeepLink {
+		t.Fatalf("Invalid schema drift %v", drift)
+	}
+
+	var extras []map[string]string
+	for reader.Next() {
+		if reader.Entry().ProductName != "" {
+			t.Fatalf("Invalid product name %s", reader.Entry().ProductName)
+		}
+		extras = append(extras, reader.Entry().Extra)
+	}
+	expected := []map[string]string{
+		{"Product_Name": "Shoe"},
+		{"Product_Name": "Boot", "colour_code": "#000000"},
+	}
+	if !reflect.DeepEqual(extras, expected) {
+		t.Fatalf("Invalid extra values %v", extras)
+	}
+}
+
+func TestFetchDataFeedSchemaDrift(t *testing.T) {
+	options := &awin.DataFeedOptions{
+		FeedIds:  []string{"1"},
+		Language: "en",
+		Columns:  []awin.DataFeedColumn{awin.ColumnAwProductId, awin.ColumnProductName, awin.ColumnSearchPrice},
+	}
+
+	for _, mode := range []awin.SchemaDriftMode{awin.SchemaDriftIgnore, awin.SchemaDriftWarn, awin.SchemaDriftStrict} {
+		logger := &recordingLogger{}
+		awinClient := awin.New("apiKey", awin.WithSchemaDrift(mode), awin.WithLogger(logger), awin.WithHttpClient(&http.Client{Transport: mockRoundTripper{
+			response:        statusResponse(200, gzipContent(t, renamedFeed))(),
+			requestTestFunc: func(r *http.Request) error { return nil },
+		}}))
+
+		entries, report, err := awinClient.FetchDataFeedWithReport(options)
+		record, logged := logger.find("warn", "awin schema drift")
+		if logged != (mode == awin.SchemaDriftWarn) {
+			t.Fatalf("%d: Invalid logging of schema drift %v", mode, logger.records)
+		}
+		if logged && !reflect.DeepEqual(record.arg("missing"), []awin.DataFeedColumn{awin.ColumnProductName}) {
+			t.Fatalf("%d: Invalid log record %v", mode, record)
+		}
+
+		if mode == awin.SchemaDriftStrict {
+			var driftErr *awin.SchemaDriftError
+			if !errors.As(err, &driftErr) || !reflect.DeepEqual(driftErr.Drift.Unknown, []string{"Product_Name", "colour_code"}) {
+				t.Fatalf("expected schema drift error, received '%v'", err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%d: err is not null '%v'", mode, err)
+		}
+		// Unknown columns that were not requested are not kept in Extra
+		if len(*entries) != 2 || (*entries)[1].Extra != nil || (*entries)[1].SearchPrice != "20.00" {
+			t.Fatalf("%d: Invalid entries %v", mode, *entries)
+		}
+		if !reflect.DeepEqual(report.Drift.Missing, []awin.DataFeedColumn{awin.ColumnProductName}) {
+			t.Fatalf("%d: Invalid report %v", mode, report)
+		}
+	}
+}
+
+func TestFetchDataFeedFromUrlCustomColumns(t *testing.T) {
+	feedUrl := "https://productdata.awin.com/datafeed/download/apikey/apiKey/language/en/fid/1/columns/aw_product_id,product_name,Fashion:size/format/csv/delimiter/,/compression/gzip/adultcontent/0/"
+	feed := "aw_product_id,product_name,Fashion:size,Fashion:fit\n" +
+		"1,Shoe,L,slim\n"
+
+	for _, mode := range []awin.SchemaDriftMode{awin.SchemaDriftIgnore, awin.SchemaDriftStrict} {
+		awinClient := awin.New("apiKey", awin.WithSchemaDrift(mode), awin.WithHttpClient(&http.Client{Transport: mockRoundTripper{
+			response:        statusResponse(200, gzipContent(t, feed))(),
+			requestTestFunc: func(r *http.Request) error { return nil },
+		}}))
+
+		entries, err := awinClient.FetchDataFeedFromUrl(feedUrl)
+		if mode == awin.SchemaDriftStrict {
+			// The requested custom column is no drift, the unrequested one is
+			var driftErr *awin.SchemaDriftError
+			if !errors.As(err, &driftErr) || !reflect.DeepEqual(driftErr.Drift.Unknown, []string{"Fashion:fit"}) || len(driftErr.Drift.Missing) != 0 {
+				t.Fatalf("expected schema drift error, received '%v'", err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%d: err is not null '%v'", mode, err)
+		}
+		if len(*entries) != 1 || !reflect.DeepEqual((*entries)[0].Extra, map[string]string{"Fashion:size": "L"}) || (*entries)[0].ProductName != "Shoe" {
+			t.Fatalf("%d: Invalid entries %v", mode, *entries)
+		}
+	}
+
+	// Strict mode accepts feeds with exactly the requested custom columns
+	awinClient := awin.New("apiKey", awin.WithSchemaDrift(awin.SchemaDriftStrict), awin.WithHttpClient(&http.Client{Transport: mockRoundTripper{
+		response:        statusResponse(200, gzipContent(t, "aw_product_id,product_name,Fashion:size\n1,Shoe,L\n"))(),
+		requestTestFunc: func(r *http.Request) error { return nil },
+	}}))
+	entries, err := awinClient.FetchDataFeedFromUrl(feedUrl)
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if len(*entries) != 1 || (*entries)[0].Extra["Fashion:size"] != "L" {
+		t.Fatalf("Invalid entries %v", *entries)
+	}
+}
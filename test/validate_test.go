@@ -0,0 +1,139 @@
+package awin_go
+
+import (
+	"bytes"
+	"errors"
+	"github.com/matthiasbruns/awin-go/awin"
+	"github.com/matthiasbruns/awin-go/awin/awintest"
+	"github.com/matthiasbruns/awin-go/awin/awinvalidate"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func validEntry() awin.DataFeedEntry {
+	return awin.DataFeedEntry{
+		AwProductId:      "1",
+		AwDeepLink:       "https://www.awin1.com/pclick.php?p=1&a=123&m=1000",
+		MerchantImageUrl: "https://shop.example.com/img/1.jpg",
+		SearchPrice:      "12,99",
+		StorePrice:       "12.99",
+		Currency:         "EUR",
+		Ean:              "4006381333931",
+		Upc:              "0360-0029-1452",
+		ProductGtin:      "",
+		Isbn:             "0-306-40615-2",
+	}
+}
+
+func TestValidatorDefaultRules(t *testing.T) {
+	validator := awinvalidate.NewValidator()
+
+	entry := validEntry()
+	if violations := validator.Validate(&entry); violations != nil {
+		t.Fatalf("Invalid violations of valid entry %v", violations)
+	}
+
+	entry.AwDeepLink = " "
+	entry.MerchantImageUrl = "shop.example.com/img/1.jpg"
+	entry.SearchPrice = "-1"
+	entry.RrpPrice = "12.99 EUR"
+	entry.Currency = "EURO"
+	entry.Ean = "4006381333932"
+	entry.Isbn = "978-0-306-40615-7"
+	entry.ProductGtin = "1234"
+
+	expected := []awinvalidate.Violation{
+		{Rule: awinvalidate.RuleRequired, Column: awin.ColumnAwDeepLink, Value: " ", Message: "is required"},
+		{Rule: awinvalidate.RuleUrl, Column: awin.ColumnAwDeepLink, Value: " ", Message: "is not an absolute http(s) url"},
+		{Rule: awinvalidate.RuleUrl, Column: awin.ColumnMerchantImageUrl, Value: "shop.example.com/img/1.jpg", Message: "is not an absolute http(s) url"},
+		{Rule: awinvalidate.RulePrice, Column: awin.ColumnSearchPrice, Value: "-1", Message: "is not a non negative number"},
+		{Rule: awinvalidate.RulePrice, Column: awin.ColumnRrpPrice, Value: "12.99 EUR", Message: "is not a non negative number"},
+		{Rule: awinvalidate.RuleCurrency, Column: awin.ColumnCurrency, Value: "EURO", Message: "is not an ISO 4217 currency code"},
+		{Rule: awinvalidate.RuleGtin, Column: awin.ColumnEan, Value: "4006381333932", Message: "has no valid GTIN check digit"},
+		{Rule: awinvalidate.RuleGtin, Column: awin.ColumnProductGtin, Value: "1234", Message: "has no valid GTIN check digit"},
+	}
+	if violations := validator.Validate(&entry); !reflect.DeepEqual(violations, expected) {
+		t.Fatalf("Invalid violations\nexpected '%v'\nreceived '%v'", expected, violations)
+	}
+
+	// GTINs need 8, 12, 13 or 14 digits, even with a matching check digit
+	for _, ean := range []string{"17", "1236", "1234565", "123456789", "12345678903", "36000291452"} {
+		entry = validEntry()
+		entry.Ean = ean
+		if violations := validator.Validate(&entry); len(violations) != 1 || violations[0].Column != awin.ColumnEan {
+			t.Fatalf("Invalid violations of ean %s: %v", ean, violations)
+		}
+	}
+
+	entry = validEntry()
+	entry.Isbn = "030640615X"
+	if violations := validator.Validate(&entry); len(violations) != 1 || violations[0].Column != awin.ColumnIsbn {
+		t.Fatalf("Invalid violations of isbn %v", violations)
+	}
+}
+
+func TestValidatorCustomRules(t *testing.T) {
+	validator := awinvalidate.NewValidator(awinvalidate.Required(awin.ColumnProductName))
+	validator.Register(
+		awinvalidate.ColumnRule("size", "is no known size", func(value string) bool {
+			return strings.Contains("XS S M L XL", value)
+		}, awin.DataFeedColumn("Fashion:size")),
+		awinvalidate.RuleFunc(func(entry *awin.DataFeedEntry) []awinvalidate.Violation {
+			if entry.SearchPrice != "" && entry.RrpPrice != "" && entry.SearchPrice > entry.RrpPrice {
+				return []awinvalidate.Violation{{Rule: "discount", Message: "search price above rrp"}}
+			}
+			return nil
+		}),
+	)
+
+	entry := awin.DataFeedEntry{ProductName: "Shoe", SearchPrice: "20", RrpPrice: "10", Extra: map[string]string{"Fashion:size": "XXL"}}
+	violations := validator.Validate(&entry)
+	if len(violations) != 2 || violations[0].Rule != "size" || violations[0].Value != "XXL" || violations[1].String() != "discount: search price above rrp" {
+		t.Fatalf("Invalid violations %v", violations)
+	}
+}
+
+func TestValidateFeed(t *testing.T) {
+	var b bytes.Buffer
+	if err := awintest.WriteDataFeedCsv(&b, awintest.GeneratorOptions{Rows: 50, Seed: 1}); err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	reader, err := awin.NewDataFeedReader(&b)
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+
+	// Generated entries only carry a valid ean, every 10th entry misses its image
+	validator := awinvalidate.NewValidator(awinvalidate.Required(awin.ColumnAwDeepLink, awin.ColumnSearchPrice), awinvalidate.Url(awin.ColumnAwDeepLink), awinvalidate.Price(awin.ColumnSearchPrice), awinvalidate.Currency(awin.ColumnCurrency))
+	validator.Register(awinvalidate.RuleFunc(func(entry *awin.DataFeedEntry) []awinvalidate.Violation {
+		if entry.AwProductId[len(entry.AwProductId)-1] == '0' {
+			return []awinvalidate.Violation{{Rule: awinvalidate.RuleRequired, Column: awin.ColumnMerchantImageUrl, Message: "is required"}}
+		}
+		return nil
+	}))
+
+	var invalid []int
+	stats, err := validator.ValidateFeed(reader, func(entry awinvalidate.InvalidEntry) error {
+		invalid = append(invalid, entry.Index)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err is not null '%v'", err)
+	}
+	if stats.Entries != 50 || stats.Invalid != 5 || stats.InvalidRatio() != 0.1 || stats.Rules[awinvalidate.RuleRequired] != 5 || stats.Columns[awin.ColumnMerchantImageUrl] != 5 {
+		t.Fatalf("Invalid stats %v", stats)
+	}
+	if !reflect.DeepEqual(invalid, []int{0, 10, 20, 30, 40}) {
+		t.Fatalf("Invalid entries reported %v", invalid)
+	}
+
+	// Errors of emit stop the validation
+	stopped := errors.New("stopped")
+	stats, err = awinvalidate.NewValidator().ValidateFeed(awin.NewEntrySliceIterator([]awin.DataFeedEntry{validEntry(), {}, {}}), func(entry awinvalidate.InvalidEntry) error {
+		return stopped
+	})
+	if err != stopped || stats.Entries != 2 || !reflect.DeepEqual(stats.RuleNames(), []string{awinvalidate.RuleRequired}) {
+		t.Fatalf("Invalid result of stopped validation %v '%v'", stats, err)
+	}
+}